@@ -117,6 +117,26 @@ var testTables = []*schema.Table{
 			},
 		},
 	},
+	{
+		Name:               "deprecated_table",
+		Description:        "Description for deprecated table",
+		Deprecated:         true,
+		DeprecationMessage: "Use `test_table` instead.",
+		RemovedIn:          "v2.0.0",
+		Columns: []schema.Column{
+			{
+				Name:       "id_col",
+				Type:       arrow.PrimitiveTypes.Int64,
+				PrimaryKey: true,
+			},
+			{
+				Name:       "old_col",
+				Type:       arrow.BinaryTypes.String,
+				Deprecated: true,
+				RemovedIn:  "v2.0.0",
+			},
+		},
+	},
 }
 
 func TestGeneratePluginDocs(t *testing.T) {
@@ -131,7 +151,7 @@ func TestGeneratePluginDocs(t *testing.T) {
 			t.Fatalf("unexpected error calling GeneratePluginDocs: %v", err)
 		}
 
-		expectFiles := []string{"test_table.md", "relation_table.md", "relation_relation_table_a.md", "relation_relation_table_b.md", "incremental_table.md", "README.md"}
+		expectFiles := []string{"test_table.md", "relation_table.md", "relation_relation_table_a.md", "relation_relation_table_b.md", "incremental_table.md", "deprecated_table.md", "README.md"}
 		for _, exp := range expectFiles {
 			t.Run(exp, func(t *testing.T) {
 				output := path.Join(tmpdir, exp)
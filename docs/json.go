@@ -15,6 +15,10 @@ type jsonTable struct {
 	Description string       `json:"description"`
 	Columns     []jsonColumn `json:"columns"`
 	Relations   []jsonTable  `json:"relations"`
+
+	Deprecated         bool   `json:"deprecated,omitempty"`
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+	RemovedIn          string `json:"removed_in,omitempty"`
 }
 
 type jsonColumn struct {
@@ -23,6 +27,9 @@ type jsonColumn struct {
 	IsPrimaryKey          bool   `json:"is_primary_key,omitempty"`
 	IsPrimaryKeyComponent bool   `json:"is_primary_key_component,omitempty"`
 	IsIncrementalKey      bool   `json:"is_incremental_key,omitempty"`
+	Deprecated            bool   `json:"deprecated,omitempty"`
+	DeprecationMessage    string `json:"deprecation_message,omitempty"`
+	RemovedIn             string `json:"removed_in,omitempty"`
 }
 
 func (g *Generator) renderTablesAsJSON(dir string) error {
@@ -50,6 +57,9 @@ func (g *Generator) jsonifyTables(tables schema.Tables) []jsonTable {
 				IsPrimaryKey:          col.PrimaryKey,
 				IsPrimaryKeyComponent: col.PrimaryKeyComponent,
 				IsIncrementalKey:      col.IncrementalKey,
+				Deprecated:            col.Deprecated,
+				DeprecationMessage:    col.DeprecationMessage,
+				RemovedIn:             col.RemovedIn,
 			}
 		}
 		jsonTables[i] = jsonTable{
@@ -58,6 +68,10 @@ func (g *Generator) jsonifyTables(tables schema.Tables) []jsonTable {
 			Description: table.Description,
 			Columns:     jsonColumns,
 			Relations:   g.jsonifyTables(table.Relations),
+
+			Deprecated:         table.Deprecated,
+			DeprecationMessage: table.DeprecationMessage,
+			RemovedIn:          table.RemovedIn,
 		}
 	}
 	return jsonTables
@@ -1,5 +1,5 @@
 
-{{. | indentToDepth}}- [{{.Name}}]({{.Name}}.md){{ if .IsIncremental}} (Incremental){{ end }}
+{{. | indentToDepth}}- [{{.Name}}]({{.Name}}.md){{ if .IsIncremental}} (Incremental){{ end }}{{ if .Deprecated}} (Deprecated){{ end }}
 {{- range $index, $rel := .Relations}}
 {{- template "all_tables_entry.md.go.tpl" $rel}}
 {{- end}}
\ No newline at end of file
@@ -3,6 +3,10 @@
 This table shows data for {{.|title}}.
 
 {{ $.Description }}
+{{- if $.Deprecated }}
+
+> **Deprecated**{{ if $.RemovedIn }}: this table will be removed in {{ $.RemovedIn }}{{ end }}.{{ if $.DeprecationMessage }} {{ $.DeprecationMessage }}{{ end }}
+{{- end }}
 {{ $length := len $.PrimaryKeys -}}
 {{ if eq $length 1 }}
 The primary key for this table is **{{ index $.PrimaryKeys 0 }}**.
@@ -40,5 +44,5 @@ The following tables depend on {{.Name}}:
 | Name          | Type          |
 | ------------- | ------------- |
 {{- range $column := $.Columns }}
-|{{$column.Name}}{{if $column.PrimaryKey}} (PK){{end}}{{if $column.IncrementalKey}} (Incremental Key){{end}}|`{{$column.Type}}`|
+|{{$column.Name}}{{if $column.PrimaryKey}} (PK){{end}}{{if $column.IncrementalKey}} (Incremental Key){{end}}{{if $column.Deprecated}} (Deprecated{{if $column.RemovedIn}}, removed in {{$column.RemovedIn}}{{end}}){{end}}|`{{$column.Type}}`|
 {{- end }}
\ No newline at end of file
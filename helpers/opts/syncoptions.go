@@ -13,5 +13,11 @@ func SchedulerOpts(o plugin.SyncOptions, additionalOpts ...scheduler.SyncOption)
 	if o.Shard != nil {
 		opts = append(opts, scheduler.WithShard(o.Shard.Num, o.Shard.Total))
 	}
+	if o.Plan != nil {
+		opts = append(opts, scheduler.WithPlan(o.Plan.MaxDepth))
+	}
+	if o.ProgressInterval > 0 {
+		opts = append(opts, scheduler.WithSyncProgress(o.ProgressInterval))
+	}
 	return append(opts, additionalOpts...)
 }
@@ -23,8 +23,10 @@ const (
 
 type Client struct {
 	client        pb.PluginClient
+	tableName     string
 	mem           map[string]versionedValue
 	changes       map[string]struct{} // changed keys
+	deletes       map[string]struct{} // deleted keys
 	mutex         *sync.RWMutex
 	schema        *arrow.Schema
 	versionedMode bool
@@ -41,8 +43,10 @@ func NewClient(ctx context.Context, conn *grpc.ClientConn, tableName string) (*C
 	c := &Client{
 		conn:          conn,
 		client:        pb.NewPluginClient(conn),
+		tableName:     tableName,
 		mem:           make(map[string]versionedValue),
 		changes:       make(map[string]struct{}),
+		deletes:       make(map[string]struct{}),
 		mutex:         &sync.RWMutex{},
 		versionedMode: table.Column(versionColumn) != nil,
 	}
@@ -143,6 +147,21 @@ func (c *Client) SetKey(_ context.Context, key string, value string) error {
 		version: c.mem[key].version + 1,
 	}
 	c.changes[key] = struct{}{}
+	delete(c.deletes, key)
+	return nil
+}
+
+// DeleteKey removes the key, it's deleted from the state table by the next Flush.
+func (c *Client) DeleteKey(_ context.Context, key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.mem[key]; !ok {
+		return nil
+	}
+	delete(c.mem, key)
+	delete(c.changes, key)
+	c.deletes[key] = struct{}{}
 	return nil
 }
 
@@ -181,14 +200,56 @@ func (c *Client) Flush(ctx context.Context) error {
 	}); err != nil {
 		return err
 	}
+	if len(c.deletes) > 0 {
+		deleteRecord, err := c.deleteRecordMessage()
+		if err != nil {
+			return err
+		}
+		if err := writeClient.Send(&pb.Write_Request{
+			Message: &pb.Write_Request_DeleteRecord{
+				DeleteRecord: deleteRecord,
+			},
+		}); err != nil {
+			return err
+		}
+	}
 	if _, err := writeClient.CloseAndRecv(); err != nil {
 		return err
 	}
 
 	c.changes = make(map[string]struct{})
+	c.deletes = make(map[string]struct{})
 	return nil
 }
 
+// deleteRecordMessage returns the message deleting the rows of the deleted keys.
+func (c *Client) deleteRecordMessage() (*pb.Write_MessageDeleteRecord, error) {
+	sc := (&schema.Table{Name: c.tableName, Columns: schema.ColumnList{{Name: keyColumn, Type: arrow.BinaryTypes.String}}}).ToArrowSchema()
+	predicates := make([]*pb.Predicate, 0, len(c.deletes))
+	for k := range c.deletes {
+		bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+		bldr.Field(0).(*array.StringBuilder).Append(k)
+		rec := bldr.NewRecordBatch()
+		bldr.Release()
+		recordBytes, err := pb.RecordToBytes(rec)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, &pb.Predicate{
+			Operator: pb.Predicate_Operator(pb.Predicate_Operator_value["EQ"]),
+			Column:   keyColumn,
+			Record:   recordBytes,
+		})
+	}
+	return &pb.Write_MessageDeleteRecord{
+		TableName: c.tableName,
+		WhereClause: []*pb.PredicatesGroup{{
+			GroupingType: pb.PredicatesGroup_GroupingType(pb.PredicatesGroup_GroupingType_value["OR"]),
+			Predicates:   predicates,
+		}},
+	}, nil
+}
+
 func (c *Client) GetKey(_ context.Context, key string) (string, error) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
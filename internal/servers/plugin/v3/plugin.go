@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	pb "github.com/cloudquery/plugin-pb-go/pb/plugin/v3"
@@ -19,10 +20,14 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 const MaxMsgSize = 100 * 1024 * 1024 // 100 MiB
 
+// SyncProgressInterval is the interval between the progress reports sent during a sync, if the request asks for them
+const SyncProgressInterval = 30 * time.Second
+
 type Server struct {
 	pb.UnimplementedPluginServer
 	Plugin    *plugin.Plugin
@@ -134,20 +139,60 @@ func (s *Server) Read(req *pb.Read_Request, stream pb.Plugin_ReadServer) error {
 		}
 	}()
 
-	for rec := range records {
-		recBytes, err := pb.RecordToBytes(rec)
+	encode := func(record arrow.RecordBatch) (proto.Message, error) {
+		recBytes, err := pb.RecordToBytes(record)
 		if err != nil {
-			return status.Errorf(codes.Internal, "failed to convert record to bytes: %v", err)
+			return nil, status.Errorf(codes.Internal, "failed to convert record to bytes: %v", err)
 		}
-		resp := &pb.Read_Response{
+		return &pb.Read_Response{
 			Record: recBytes,
-		}
-		if err := stream.Send(resp); err != nil {
+		}, nil
+	}
+	send := func(msg proto.Message) error {
+		if err := stream.Send(msg.(*pb.Read_Response)); err != nil {
 			return status.Errorf(codes.Internal, "failed to send read response: %v", err)
 		}
+		return nil
+	}
+	var dropped int64
+	oversized := func(record arrow.RecordBatch, _ int) error {
+		addOversizedRows(ctx, table.Name, record.NumRows())
+		dropped += record.NumRows()
+		return nil
+	}
+	for rec := range records {
+		if err := sendRecordSplit(rec, MaxMsgSize, encode, send, oversized); err != nil {
+			return err
+		}
+	}
+
+	if readErr != nil {
+		return readErr
+	}
+	if dropped > 0 {
+		// the read response has no room for errors, so the dropped rows are reported once all the others were sent
+		return status.Errorf(codes.ResourceExhausted, "%d rows of table %s exceed the max message size (%d bytes) and were skipped", dropped, table.Name, MaxMsgSize)
 	}
+	return nil
+}
 
-	return readErr
+// logSyncSummary logs the totals of the summary, and the summary of every table and client at debug level as there
+// can be many of them.
+func (s *Server) logSyncSummary(summary *message.SyncSummary) {
+	for _, table := range summary.Tables {
+		for _, client := range table.Clients {
+			s.Logger.Debug().
+				Str("table", table.TableName).
+				Str("client", client.ClientID).
+				Uint64("resources", client.Resources).
+				Uint64("errors", client.Errors).
+				Uint64("panics", client.Panics).
+				Dur("duration_ms", client.Duration).
+				Strs("error_samples", client.ErrorSamples).
+				Msg("table sync summary")
+		}
+	}
+	s.Logger.Info().Uint64("resources", summary.TotalResources()).Uint64("errors", summary.TotalErrors()).Int("tables", len(summary.Tables)).Msg("sync summary")
 }
 
 func flushMetrics() {
@@ -172,6 +217,9 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 		SkipDependentTables: req.SkipDependentTables,
 		DeterministicCQID:   req.DeterministicCqId,
 	}
+	if req.WithProgress {
+		syncOptions.ProgressInterval = SyncProgressInterval
+	}
 	if req.Backend != nil {
 		syncOptions.BackendOptions = &plugin.BackendOptions{
 			TableName:  req.Backend.TableName,
@@ -185,6 +233,12 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 		}
 	}
 
+	// deprecated tables explicitly selected by name are reported once the sync resolved them
+	selected := make(map[string]struct{}, len(req.Tables))
+	for _, name := range req.Tables {
+		selected[name] = struct{}{}
+	}
+
 	go func() {
 		defer flushMetrics()
 		defer close(msgs)
@@ -203,6 +257,9 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 		pbMsg := &pb.Sync_Response{}
 		switch m := msg.(type) {
 		case *message.SyncMigrateTable:
+			if _, ok := selected[m.Table.Name]; ok && m.Table.Deprecated {
+				s.Logger.Warn().Str("table", m.Table.Name).Str("removed_in", m.Table.RemovedIn).Msgf("table selected for sync is %s", m.Table.DeprecationNotice())
+			}
 			tableSchema := m.Table.ToArrowSchema()
 			schemaBytes, err := pb.SchemaToBytes(tableSchema)
 			if err != nil {
@@ -215,15 +272,10 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 			}
 
 		case *message.SyncInsert:
-			recordBytes, err := pb.RecordToBytes(m.Record)
-			if err != nil {
-				return status.Errorf(codes.Internal, "failed to encode record: %v", err)
-			}
-			pbMsg.Message = &pb.Sync_Response_Insert{
-				Insert: &pb.Sync_MessageInsert{
-					Record: recordBytes,
-				},
+			if err := s.sendSyncInsert(ctx, stream, m.Record); err != nil {
+				return err
 			}
+			continue
 		case *message.SyncDeleteRecord:
 			whereClause := make([]*pb.PredicatesGroup, len(m.WhereClause))
 			for j, predicateGroup := range m.WhereClause {
@@ -259,6 +311,28 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 					WhereClause:    whereClause,
 				},
 			}
+		case *message.SyncSummary:
+			// There is no protocol message for the summary, so it is handed to the plugin and logged instead of being sent
+			s.logSyncSummary(m)
+			if err := s.Plugin.OnSyncSummary(ctx, m); err != nil {
+				syncErr = fmt.Errorf("failed to handle sync summary: %w", err)
+				return syncErr
+			}
+			continue
+		case *message.SyncProgress:
+			if err := s.Plugin.OnSyncProgress(ctx, m); err != nil {
+				syncErr = fmt.Errorf("failed to handle sync progress: %w", err)
+				return syncErr
+			}
+			pbMsg.Message = &pb.Sync_Response_Progress{
+				Progress: &pb.Sync_MessageProgress{
+					TablesInProgress: m.TablesInProgress,
+					Resources:        m.Resources,
+					CompletedWork:    m.CompletedWork,
+					PlannedWork:      m.PlannedWork,
+					Elapsed:          durationpb.New(m.Elapsed),
+				},
+			}
 		case *message.SyncError:
 			if !req.WithErrorMessages {
 				continue
@@ -290,6 +364,44 @@ func (s *Server) Sync(req *pb.Sync_Request, stream pb.Plugin_SyncServer) error {
 	return syncErr
 }
 
+// sendSyncInsert sends the record as one or more insert messages, splitting it if the encoded message exceeds MaxMsgSize.
+// Rows that don't fit even on their own are counted and reported as SyncError messages, whether error messages were
+// requested or not, as the rows are lost otherwise.
+func (*Server) sendSyncInsert(ctx context.Context, stream pb.Plugin_SyncServer, record arrow.RecordBatch) error {
+	encode := func(record arrow.RecordBatch) (proto.Message, error) {
+		recordBytes, err := pb.RecordToBytes(record)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode record: %v", err)
+		}
+		return &pb.Sync_Response{
+			Message: &pb.Sync_Response_Insert{
+				Insert: &pb.Sync_MessageInsert{
+					Record: recordBytes,
+				},
+			},
+		}, nil
+	}
+	send := func(msg proto.Message) error {
+		if err := stream.Send(msg.(*pb.Sync_Response)); err != nil {
+			return status.Errorf(codes.Internal, "failed to send message: %v", err)
+		}
+		return nil
+	}
+	oversized := func(record arrow.RecordBatch, size int) error {
+		tableName := recordTableName(record)
+		addOversizedRows(ctx, tableName, record.NumRows())
+		return send(&pb.Sync_Response{
+			Message: &pb.Sync_Response_Error{
+				Error: &pb.Sync_MessageError{
+					TableName: tableName,
+					Error:     fmt.Sprintf("row exceeds max message size (%d > %d bytes) and was skipped", size, MaxMsgSize),
+				},
+			},
+		})
+	}
+	return sendRecordSplit(record, MaxMsgSize, encode, send, oversized)
+}
+
 func (s *Server) Write(stream pb.Plugin_WriteServer) error {
 	msgs := make(chan message.WriteMessage)
 	ctx := stream.Context()
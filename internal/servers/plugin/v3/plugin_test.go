@@ -14,6 +14,7 @@ import (
 	"github.com/apache/arrow-go/v18/arrow/memory"
 	pb "github.com/cloudquery/plugin-pb-go/pb/plugin/v3"
 	"github.com/cloudquery/plugin-sdk/v4/internal/memdb"
+	"github.com/cloudquery/plugin-sdk/v4/message"
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/rs/zerolog"
@@ -55,8 +56,8 @@ type mockSyncServer struct {
 	messages []*pb.Sync_Response
 }
 
-func (s *mockSyncServer) Send(*pb.Sync_Response) error {
-	s.messages = append(s.messages, &pb.Sync_Response{})
+func (s *mockSyncServer) Send(msg *pb.Sync_Response) error {
+	s.messages = append(s.messages, msg)
 	return nil
 }
 
@@ -233,6 +234,90 @@ func TestTransformSchema(t *testing.T) {
 	}
 }
 
+type progressPluginClient struct {
+	plugin.UnimplementedDestination
+}
+
+func (*progressPluginClient) Tables(context.Context, plugin.TableOptions) (schema.Tables, error) {
+	return nil, nil
+}
+
+func (*progressPluginClient) Sync(_ context.Context, options plugin.SyncOptions, res chan<- message.SyncMessage) error {
+	if options.ProgressInterval > 0 {
+		res <- &message.SyncProgress{TablesInProgress: []string{"test"}, Resources: 2, CompletedWork: 1, PlannedWork: 3, Elapsed: time.Second}
+	}
+	return nil
+}
+
+func (*progressPluginClient) Close(context.Context) error { return nil }
+
+func TestPluginSyncProgress(t *testing.T) {
+	ctx := context.Background()
+	s := Server{
+		Plugin: plugin.NewPlugin("test", "development", func(context.Context, zerolog.Logger, []byte, plugin.NewClientOptions) (plugin.Client, error) {
+			return &progressPluginClient{}, nil
+		}),
+	}
+	_, err := s.Init(ctx, &pb.Init_Request{})
+	require.NoError(t, err)
+
+	streamSyncServer := &mockSyncServer{}
+	require.NoError(t, s.Sync(&pb.Sync_Request{}, streamSyncServer))
+	require.Empty(t, streamSyncServer.messages)
+
+	streamSyncServer = &mockSyncServer{}
+	require.NoError(t, s.Sync(&pb.Sync_Request{WithProgress: true}, streamSyncServer))
+	require.Len(t, streamSyncServer.messages, 1)
+	progress := streamSyncServer.messages[0].Message.(*pb.Sync_Response_Progress).Progress
+	require.Equal(t, []string{"test"}, progress.TablesInProgress)
+	require.EqualValues(t, 2, progress.Resources)
+	require.EqualValues(t, 1, progress.CompletedWork)
+	require.EqualValues(t, 3, progress.PlannedWork)
+	require.Equal(t, time.Second, progress.Elapsed.AsDuration())
+}
+
+type deprecatedTablePluginClient struct {
+	plugin.UnimplementedDestination
+}
+
+func (*deprecatedTablePluginClient) Tables(context.Context, plugin.TableOptions) (schema.Tables, error) {
+	return nil, nil
+}
+
+func (*deprecatedTablePluginClient) Sync(_ context.Context, _ plugin.SyncOptions, res chan<- message.SyncMessage) error {
+	for _, name := range []string{"test_deprecated", "test_deprecated_wildcard", "test_current"} {
+		table := &schema.Table{Name: name, Columns: schema.ColumnList{{Name: "test", Type: arrow.BinaryTypes.String}}}
+		table.Deprecated = name != "test_current"
+		table.RemovedIn = "v2.0.0"
+		res <- &message.SyncMigrateTable{Table: table}
+	}
+	return nil
+}
+
+func (*deprecatedTablePluginClient) Close(context.Context) error { return nil }
+
+func TestPluginSyncDeprecatedTables(t *testing.T) {
+	ctx := context.Background()
+	var logs strings.Builder
+	s := Server{
+		Plugin: plugin.NewPlugin("test", "development", func(context.Context, zerolog.Logger, []byte, plugin.NewClientOptions) (plugin.Client, error) {
+			return &deprecatedTablePluginClient{}, nil
+		}),
+		Logger: zerolog.New(&logs),
+	}
+	_, err := s.Init(ctx, &pb.Init_Request{})
+	require.NoError(t, err)
+
+	streamSyncServer := &mockSyncServer{}
+	require.NoError(t, s.Sync(&pb.Sync_Request{Tables: []string{"test_deprecated", "test_current", "test_deprecated_*"}}, streamSyncServer))
+	require.Len(t, streamSyncServer.messages, 3)
+
+	// only the deprecated tables selected by name are reported
+	require.Equal(t, 1, strings.Count(logs.String(), "table selected for sync is"))
+	require.Contains(t, logs.String(), `"table":"test_deprecated"`)
+	require.Contains(t, logs.String(), `"removed_in":"v2.0.0"`)
+}
+
 type mockSourceColumnAdderPluginClient struct {
 	plugin.UnimplementedDestination
 	plugin.UnimplementedSource
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"github.com/cloudquery/plugin-sdk/v4/scheduler/metrics"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+)
+
+const oversizedRowsMetricName = "sync.table.oversized_rows"
+
+var (
+	oversizedRows     otelmetric.Int64Counter
+	oversizedRowsOnce sync.Once
+)
+
+// addOversizedRows records rows that were dropped because they do not fit in a single message, even on their own.
+func addOversizedRows(ctx context.Context, tableName string, count int64) {
+	oversizedRowsOnce.Do(func() {
+		oversizedRows, _ = otel.Meter(metrics.ResourceName).Int64Counter(oversizedRowsMetricName,
+			otelmetric.WithDescription("Number of rows dropped because they exceed the max message size"),
+			otelmetric.WithUnit("/{tot}"),
+		)
+	})
+	oversizedRows.Add(ctx, count, otelmetric.WithAttributes(attribute.Key("sync.table.name").String(tableName)))
+}
+
+// sendRecordSplit sends the record as one or more messages that fit in maxSize.
+// The size of the record is estimated from its buffers first, so that a record that is clearly too large is split in
+// parts expected to fit without being encoded. Each part is then sent by sendRecordEncoded.
+// The slices of the record are released once sent.
+func sendRecordSplit(record arrow.RecordBatch, maxSize int, encode func(arrow.RecordBatch) (proto.Message, error), send func(proto.Message) error, oversized func(arrow.RecordBatch, int) error) error {
+	rows := record.NumRows()
+	estimated := util.TotalRecordSize(record)
+	if estimated <= int64(maxSize) || rows <= 1 {
+		return sendRecordEncoded(record, maxSize, encode, send, oversized)
+	}
+
+	// slices share the buffers of the record, so the size of the parts is estimated by their number of rows
+	parts := min((estimated+int64(maxSize)-1)/int64(maxSize), rows)
+	step := (rows + parts - 1) / parts
+	for offset := int64(0); offset < rows; offset += step {
+		slice := record.NewSlice(offset, min(offset+step, rows))
+		err := sendRecordEncoded(slice, maxSize, encode, send, oversized)
+		slice.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendRecordEncoded encodes the record and sends it if the encoded message fits in maxSize.
+// Otherwise, the record is split in halves recursively until every part fits.
+// Single rows that still exceed maxSize are passed to oversized along with their encoded size.
+func sendRecordEncoded(record arrow.RecordBatch, maxSize int, encode func(arrow.RecordBatch) (proto.Message, error), send func(proto.Message) error, oversized func(arrow.RecordBatch, int) error) error {
+	msg, err := encode(record)
+	if err != nil {
+		return err
+	}
+	size := proto.Size(msg)
+	if size <= maxSize {
+		return send(msg)
+	}
+	if record.NumRows() <= 1 {
+		return oversized(record, size)
+	}
+
+	middle := record.NumRows() / 2
+	for _, bounds := range [][2]int64{{0, middle}, {middle, record.NumRows()}} {
+		slice := record.NewSlice(bounds[0], bounds[1])
+		err := sendRecordEncoded(slice, maxSize, encode, send, oversized)
+		slice.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordTableName(record arrow.RecordBatch) string {
+	md := record.Schema().Metadata()
+	tableName, _ := md.GetValue(schema.MetadataTableName)
+	return tableName
+}
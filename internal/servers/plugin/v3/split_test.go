@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func makeRecordFromStrings(mem memory.Allocator, values ...string) arrow.RecordBatch {
+	str := array.NewStringBuilder(mem)
+	defer str.Release()
+	for _, v := range values {
+		str.AppendString(v)
+	}
+	arr := str.NewStringArray()
+	defer arr.Release()
+	sch := arrow.NewSchema([]arrow.Field{{Name: "col1", Type: arrow.BinaryTypes.String}}, nil)
+
+	return array.NewRecordBatch(sch, []arrow.Array{arr}, int64(len(values)))
+}
+
+// encodeValues encodes the record as the concatenation of its string values, so that message sizes are predictable.
+func encodeValues(record arrow.RecordBatch) (proto.Message, error) {
+	var b []byte
+	col := record.Column(0).(*array.String)
+	for i := 0; i < col.Len(); i++ {
+		b = append(b, col.Value(i)...)
+	}
+	return wrapperspb.Bytes(b), nil
+}
+
+func TestSendRecordSplit(t *testing.T) {
+	const maxSize = 1024
+	small := strings.Repeat("a", 200)
+	large := strings.Repeat("b", maxSize*2)
+
+	cases := []struct {
+		name          string
+		values        []string
+		wantEncoded   int
+		wantSent      int
+		wantOversized int
+	}{
+		{name: "fits", values: []string{small}, wantEncoded: 1, wantSent: 1},
+		// the record is split by its estimated size, without being encoded
+		{name: "split", values: slices.Repeat([]string{small}, 8), wantEncoded: 2, wantSent: 2},
+		{name: "oversized_row", values: []string{small, large, small}, wantEncoded: 3, wantSent: 2, wantOversized: 1},
+		{name: "all_oversized", values: []string{large, large}, wantEncoded: 2, wantOversized: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			record := makeRecordFromStrings(mem, tc.values...)
+			var encoded, sent, oversized int
+			encode := func(record arrow.RecordBatch) (proto.Message, error) {
+				encoded++
+				return encodeValues(record)
+			}
+			err := sendRecordSplit(record, maxSize, encode, func(msg proto.Message) error {
+				require.LessOrEqual(t, proto.Size(msg), maxSize)
+				sent++
+				return nil
+			}, func(record arrow.RecordBatch, size int) error {
+				require.EqualValues(t, 1, record.NumRows())
+				require.Greater(t, size, maxSize)
+				oversized++
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.wantEncoded, encoded)
+			require.Equal(t, tc.wantSent, sent)
+			require.Equal(t, tc.wantOversized, oversized)
+
+			// the slices are released once sent
+			record.Release()
+			mem.AssertSize(t, 0)
+		})
+	}
+}
+
+func TestSendRecordEncoded(t *testing.T) {
+	const maxSize = 32
+	small := "0123456789"
+
+	// records that don't fit once encoded are bisected
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	record := makeRecordFromStrings(mem, small, small, small, small)
+	var encoded, sent int
+	encode := func(record arrow.RecordBatch) (proto.Message, error) {
+		encoded++
+		return encodeValues(record)
+	}
+	err := sendRecordEncoded(record, maxSize, encode, func(msg proto.Message) error {
+		require.LessOrEqual(t, proto.Size(msg), maxSize)
+		sent++
+		return nil
+	}, func(arrow.RecordBatch, int) error {
+		t.Fatal("unexpected oversized row")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, encoded)
+	require.Equal(t, 2, sent)
+
+	record.Release()
+	mem.AssertSize(t, 0)
+}
@@ -2,6 +2,7 @@ package message
 
 import (
 	"slices"
+	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
@@ -131,3 +132,88 @@ type SyncError struct {
 func (e SyncError) GetTable() *schema.Table {
 	return &schema.Table{Name: e.TableName}
 }
+
+// SyncSummary is sent at the end of a sync, if requested, with the statistics of every table that was synced.
+type SyncSummary struct {
+	syncBaseMessage
+	Tables []TableSyncSummary
+	// Planned is set when the sync ran in plan mode. Only table resolvers were called, so the resources of every
+	// client are the number of items returned by the table resolver.
+	Planned bool
+}
+
+// TableSyncSummary holds the statistics of a single table, broken down by client.
+type TableSyncSummary struct {
+	TableName string
+	// Duration is the time between the first client starting to resolve the table and the last one finishing
+	Duration time.Duration
+	Clients  []ClientSyncSummary
+}
+
+// ClientSyncSummary holds the statistics of a single table and client pair.
+type ClientSyncSummary struct {
+	ClientID  string
+	Resources uint64
+	Errors    uint64
+	Panics    uint64
+	// Retries is the number of resolver calls retried by the retry policy
+	Retries  uint64
+	Duration time.Duration
+	// ErrorSamples holds the messages of the first few errors
+	ErrorSamples []string
+}
+
+func (SyncSummary) GetTable() *schema.Table {
+	return &schema.Table{}
+}
+
+// TotalResources returns the number of resources synced across all tables and clients.
+func (m SyncSummary) TotalResources() uint64 {
+	var total uint64
+	for _, table := range m.Tables {
+		for _, client := range table.Clients {
+			total += client.Resources
+		}
+	}
+	return total
+}
+
+// TotalErrors returns the number of errors across all tables and clients.
+func (m SyncSummary) TotalErrors() uint64 {
+	var total uint64
+	for _, table := range m.Tables {
+		for _, client := range table.Clients {
+			total += client.Errors
+		}
+	}
+	return total
+}
+
+// SyncProgress is sent periodically during a sync, if requested, with the progress of the top-level tables.
+// Work is counted in top-level table and client pairs, including their relations.
+type SyncProgress struct {
+	syncBaseMessage
+	// TablesInProgress holds the sorted names of the top-level tables being resolved for at least one client
+	TablesInProgress []string
+	// Resources is the number of resources sent so far
+	Resources uint64
+	// CompletedWork is the number of top-level table clients finished so far
+	CompletedWork uint64
+	// PlannedWork is the number of top-level table clients to sync. Tables depending on other tables are only
+	// planned once their dependencies are synced, so it can grow during the sync.
+	PlannedWork uint64
+	// Elapsed is the time since the sync started
+	Elapsed time.Duration
+}
+
+func (SyncProgress) GetTable() *schema.Table {
+	return &schema.Table{}
+}
+
+// RemainingWork returns the number of planned top-level table clients that are not finished yet.
+func (m SyncProgress) RemainingWork() uint64 {
+	if m.CompletedWork >= m.PlannedWork {
+		return 0
+	}
+	return m.PlannedWork - m.CompletedWork
+}
@@ -39,6 +39,10 @@ func (messages WriteMessages) GetInserts() WriteInserts {
 	return slices.Clip(inserts)
 }
 
+// WriteMigrateTable asks the destination to migrate a table to the given definition. Columns of the table carry their
+// schema.Column.PreviousNames, so that destinations supporting it can get the renames and safe type widenings against
+// the existing table from schema.Table.GetChanges with schema.WithColumnRenames and schema.WithTypeWidening, and
+// migrate them without data loss.
 type WriteMigrateTable struct {
 	writeBaseMessage
 	Table        *schema.Table
@@ -210,6 +210,34 @@ func (p *Plugin) OnSyncFinish(ctx context.Context) error {
 	return nil
 }
 
+// OnSyncSummaryHandler is an interface that can be implemented by a plugin client to receive the summary
+// sent at the end of a sync, see scheduler.WithSyncSummary.
+type OnSyncSummaryHandler interface {
+	OnSyncSummary(context.Context, *message.SyncSummary) error
+}
+
+// OnSyncSummary gets called when a sync sends a summary, before the sync finishes.
+func (p *Plugin) OnSyncSummary(ctx context.Context, summary *message.SyncSummary) error {
+	if v, ok := p.client.(OnSyncSummaryHandler); ok {
+		return v.OnSyncSummary(ctx, summary)
+	}
+	return nil
+}
+
+// OnSyncProgressHandler is an interface that can be implemented by a plugin client to receive the progress
+// reports sent during a sync, see scheduler.WithSyncProgress.
+type OnSyncProgressHandler interface {
+	OnSyncProgress(context.Context, *message.SyncProgress) error
+}
+
+// OnSyncProgress gets called when a sync sends a progress report.
+func (p *Plugin) OnSyncProgress(ctx context.Context, progress *message.SyncProgress) error {
+	if v, ok := p.client.(OnSyncProgressHandler); ok {
+		return v.OnSyncProgress(ctx, progress)
+	}
+	return nil
+}
+
 func (p *Plugin) Targets() []BuildTarget {
 	return p.targets
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/cloudquery/plugin-sdk/v4/glob"
 	"github.com/cloudquery/plugin-sdk/v4/message"
@@ -21,6 +22,13 @@ type Shard struct {
 	Total int32
 }
 
+// PlanOptions enables plan mode: the sync only calls table resolvers to report the (table, client) pairs
+// it would sync and the number of items they return, without sending any data.
+type PlanOptions struct {
+	// MaxDepth is the depth of relations whose table resolvers are called, 1 means top-level tables only
+	MaxDepth int
+}
+
 type SyncOptions struct {
 	Tables              []string
 	SkipTables          []string
@@ -28,6 +36,10 @@ type SyncOptions struct {
 	DeterministicCQID   bool
 	BackendOptions      *BackendOptions
 	Shard               *Shard
+	Plan                *PlanOptions
+	// ProgressInterval is the interval between progress reports, see scheduler.WithSyncProgress.
+	// Progress isn't reported if it's 0.
+	ProgressInterval time.Duration
 }
 
 type SourceClient interface {
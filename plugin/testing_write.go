@@ -46,12 +46,16 @@ type SafeMigrations struct {
 	ChangeColumn           bool
 	RemoveUniqueConstraint bool
 	MovePKToCQOnly         bool
+	AddIndex               bool
+	RemoveIndex            bool
 }
 
 // Migrations defines which migrations should be skipped completely
 type Migrations struct {
 	RemoveUniqueConstraint bool
 	MovePKToCQOnly         bool
+	AddIndex               bool
+	RemoveIndex            bool
 }
 
 // WriteTests defines which tests should be skipped in the write test suite
@@ -249,6 +249,68 @@ func (s *WriterTestSuite) testMigrate(
 		}
 	})
 
+	t.Run("add_index"+suffix, func(t *testing.T) {
+		if s.tests.SkipSpecificMigrations.AddIndex {
+			t.Skip("skipping test completely: add_index")
+		}
+		if !forceMigrate && !s.tests.SafeMigrations.AddIndex {
+			t.Skip("skipping test: add_index")
+		}
+		tableName := "cq_add_index" + suffix + "_" + tableUUIDSuffix()
+		source := &schema.Table{
+			Name: tableName,
+			Columns: schema.ColumnList{
+				{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "uuid", Type: types.ExtensionTypes.UUID},
+				{Name: "bool", Type: arrow.FixedWidthTypes.Boolean},
+			}}
+		target := &schema.Table{
+			Name: tableName,
+			Columns: schema.ColumnList{
+				{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "uuid", Type: types.ExtensionTypes.UUID},
+				{Name: "bool", Type: arrow.FixedWidthTypes.Boolean},
+			},
+			Indexes: []schema.Index{{Name: tableName + "_uuid_bool_idx", Columns: []string{"uuid", "bool"}}},
+		}
+		// index changes never require dropping the table, even when forced
+		require.NoError(t, s.migrate(ctx, target, source, true, forceMigrate))
+		if !forceMigrate {
+			require.NoError(t, s.migrate(ctx, target, target, true, false))
+		}
+	})
+
+	t.Run("remove_index"+suffix, func(t *testing.T) {
+		if s.tests.SkipSpecificMigrations.RemoveIndex {
+			t.Skip("skipping test completely: remove_index")
+		}
+		if !forceMigrate && !s.tests.SafeMigrations.RemoveIndex {
+			t.Skip("skipping test: remove_index")
+		}
+		tableName := "cq_remove_index" + suffix + "_" + tableUUIDSuffix()
+		source := &schema.Table{
+			Name: tableName,
+			Columns: schema.ColumnList{
+				{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "uuid", Type: types.ExtensionTypes.UUID},
+				{Name: "bool", Type: arrow.FixedWidthTypes.Boolean},
+			},
+			Indexes: []schema.Index{{Name: tableName + "_uuid_idx", Columns: []string{"uuid"}}},
+		}
+		target := &schema.Table{
+			Name: tableName,
+			Columns: schema.ColumnList{
+				{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "uuid", Type: types.ExtensionTypes.UUID},
+				{Name: "bool", Type: arrow.FixedWidthTypes.Boolean},
+			}}
+		// index changes never require dropping the table, even when forced
+		require.NoError(t, s.migrate(ctx, target, source, true, forceMigrate))
+		if !forceMigrate {
+			require.NoError(t, s.migrate(ctx, target, target, true, false))
+		}
+	})
+
 	t.Run("remove_unique_constraint_only"+suffix, func(t *testing.T) {
 		if s.tests.SkipSpecificMigrations.RemoveUniqueConstraint {
 			t.Skip("skipping test completely: remove_unique_constraint_only")
@@ -19,6 +19,18 @@ func validateTables(tables schema.Tables) error {
 		return fmt.Errorf("found duplicate columns in plugin: %w", err)
 	}
 
+	if err := tables.ValidateDependencies(); err != nil {
+		return fmt.Errorf("found invalid table dependencies in plugin: %w", err)
+	}
+
+	if err := tables.ValidatePartitioning(); err != nil {
+		return fmt.Errorf("found invalid table partitioning in plugin: %w", err)
+	}
+
+	if err := tables.ValidateIndexes(); err != nil {
+		return fmt.Errorf("found invalid table indexes in plugin: %w", err)
+	}
+
 	return nil
 }
 
@@ -32,7 +44,15 @@ func (p *Plugin) validate(ctx context.Context) error {
 		return fmt.Errorf("failed to get tables: %w", err)
 	}
 
-	return validateTables(tables)
+	if err := validateTables(tables); err != nil {
+		return err
+	}
+
+	if err := tables.ValidateRemovals(p.version); err != nil {
+		return fmt.Errorf("found tables or columns that should have been removed in plugin version %s: %w", p.version, err)
+	}
+
+	return nil
 }
 
 func JSONSchemaValidator(jsonSchema string) (*jsonschema.Schema, error) {
@@ -57,18 +57,24 @@ func WithBatchTimeout(timeout time.Duration) BatchOption {
 	}
 }
 
-func (s *BatchSettings) getBatcher(ctx context.Context, res chan<- message.SyncMessage, logger zerolog.Logger) batcherInterface {
+func (s *BatchSettings) getBatcher(ctx context.Context, res chan<- message.SyncMessage, logger zerolog.Logger, inFlight *inFlight) batcherInterface {
 	if s == nil || s.Timeout <= 0 || s.MaxRows <= 0 {
-		return &nopBatcher{res: res}
+		return &nopBatcher{res: res, inFlight: inFlight}
 	}
 
-	return &batcher{
-		done:    ctx.Done(),
-		res:     res,
-		maxRows: s.MaxRows,
-		timeout: s.Timeout,
-		logger:  logger.With().Int("max_rows", s.MaxRows).Dur("timeout_ms", s.Timeout).Logger(),
+	b := &batcher{
+		done:     ctx.Done(),
+		res:      res,
+		maxRows:  s.MaxRows,
+		timeout:  s.Timeout,
+		inFlight: inFlight,
+		logger:   logger.With().Int("max_rows", s.MaxRows).Dur("timeout_ms", s.Timeout).Logger(),
 	}
+	if inFlight != nil {
+		// the memory budget is freed up by sending the pending batches
+		inFlight.flush = b.flush
+	}
+	return b
 }
 
 type batcherInterface interface {
@@ -77,11 +83,14 @@ type batcherInterface interface {
 }
 
 type nopBatcher struct {
-	res chan<- message.SyncMessage
+	res      chan<- message.SyncMessage
+	inFlight *inFlight
 }
 
 func (n *nopBatcher) process(resource *schema.Resource) {
+	ticket := n.inFlight.take(resource)
 	n.res <- &message.SyncInsert{Record: resource.GetValues().ToArrowRecord(resource.Table.ToArrowSchema())}
+	n.inFlight.sent(ticket)
 }
 
 func (*nopBatcher) close() {}
@@ -96,6 +105,9 @@ type batcher struct {
 	maxRows int
 	timeout time.Duration
 
+	// inFlight is acknowledged once the resources are sent
+	inFlight *inFlight
+
 	// using sync primitives by value here implies that batcher is to be used by pointer only
 	// workers is a sync.Map rather than a map + mutex pair
 	// because worker allocation & lookup falls into one of the sync.Map use-cases,
@@ -113,6 +125,10 @@ type worker struct {
 	builder          *array.RecordBuilder // we can reuse that
 	res              chan<- message.SyncMessage
 
+	// tickets of the buffered rows, released once they're sent
+	inFlight *inFlight
+	tickets  []inFlightTicket
+
 	// debug logging
 	tableName string
 	logger    *zerolog.Logger
@@ -125,6 +141,38 @@ func (w *worker) send() {
 	// we need to reserve here as NewRecord (& underlying NewArray calls) reset the memory
 	w.builder.Reserve(w.maxRows)
 	w.curRows = 0 // reset
+	w.inFlight.sent(w.tickets...)
+	w.tickets = w.tickets[:0]
+}
+
+// appendResource appends the resource to the builder, reporting whether the batch was sent because it was full.
+func (w *worker) appendResource(r *schema.Resource) bool {
+	scalar.AppendToRecordBuilder(w.builder, r.GetValues())
+	w.curRows++
+	if w.inFlight != nil {
+		w.tickets = append(w.tickets, w.inFlight.take(r))
+	}
+	// check if we need to flush
+	if w.maxRows > 0 && w.curRows == w.maxRows {
+		w.send()
+		return true
+	}
+	return false
+}
+
+// appendQueued appends the resources queued in ch without blocking, reporting false if ch was closed.
+func (w *worker) appendQueued() bool {
+	for {
+		select {
+		case r, ok := <-w.ch:
+			if !ok {
+				return false
+			}
+			w.appendResource(r)
+		default:
+			return true
+		}
+	}
 }
 
 func (w *worker) work(done <-chan struct{}, timeout time.Duration) {
@@ -142,12 +190,7 @@ func (w *worker) work(done <-chan struct{}, timeout time.Duration) {
 				return
 			}
 
-			// append to builder
-			scalar.AppendToRecordBuilder(w.builder, r.GetValues())
-			w.curRows++
-			// check if we need to flush
-			if w.maxRows > 0 && w.curRows == w.maxRows {
-				w.send()
+			if w.appendResource(r) {
 				ticker.Reset(timeout)
 			}
 
@@ -157,6 +200,14 @@ func (w *worker) work(done <-chan struct{}, timeout time.Duration) {
 			}
 
 		case ch := <-w.flush:
+			// append the queued resources first, so that the flush frees up their memory too
+			if !w.appendQueued() {
+				if w.curRows > 0 {
+					w.send()
+				}
+				close(ch)
+				return
+			}
 			if w.curRows > 0 {
 				w.send()
 				ticker.Reset(timeout)
@@ -181,7 +232,7 @@ func (b *batcher) process(res *schema.Resource) {
 
 	// we alloc only ch here, as it may be needed right away
 	// for instance, if another goroutine will get the value allocated by us
-	wr := &worker{ch: make(chan *schema.Resource, 5)} // 5 is quite enough
+	wr := &worker{ch: make(chan *schema.Resource, 5), flush: make(chan chan struct{}, 1)} // 5 is quite enough
 	v, loaded = b.workers.LoadOrStore(table.Name, wr)
 	if loaded {
 		// means that the worker was already in tne sync.Map, so we just discard the wr value
@@ -197,10 +248,10 @@ func (b *batcher) process(res *schema.Resource) {
 		defer b.wg.Done()
 
 		// fill in the worker fields
-		wr.flush = make(chan chan struct{})
 		wr.maxRows = b.maxRows
 		wr.builder = array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
 		wr.res = b.res
+		wr.inFlight = b.inFlight
 		wr.builder.Reserve(b.maxRows)
 		wr.logger = &b.logger
 		wr.tableName = table.Name
@@ -212,6 +263,18 @@ func (b *batcher) process(res *schema.Resource) {
 	wr.ch <- res
 }
 
+// flush asks all the workers to send their buffered rows, without waiting for them.
+func (b *batcher) flush() {
+	b.workers.Range(func(_, v any) bool {
+		select {
+		case v.(*worker).flush <- make(chan struct{}):
+		default:
+			// a flush is already pending
+		}
+		return true
+	})
+}
+
 func (b *batcher) close() {
 	b.workers.Range(func(_, v any) bool {
 		close(v.(*worker).ch)
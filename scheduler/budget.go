@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"golang.org/x/sync/semaphore"
+)
+
+var errSyncDeadlineExceeded = errors.New("sync deadline exceeded")
+
+// WithSyncDeadline sets a wall-clock deadline for the sync. Once the deadline passes, no new tables or relations are
+// started and the resolvers already in flight are left to drain. A SyncError is then sent for every table that was
+// skipped or cut short. Unlike cancelling the context, all the resolved resources are still delivered.
+func WithSyncDeadline(deadline time.Time) SyncOption {
+	return func(s *syncClient) {
+		s.budget = &syncBudget{
+			deadline: deadline,
+			skipped:  make(map[string]map[string]struct{}),
+			cut:      make(map[string]map[string]struct{}),
+		}
+	}
+}
+
+// syncBudget tracks the tables skipped because the sync deadline passed.
+type syncBudget struct {
+	deadline time.Time
+
+	mu sync.Mutex
+	// skipped holds the IDs of the clients each table was not started for, by table name
+	skipped map[string]map[string]struct{}
+	// cut holds the IDs of the clients each table was cut short for (some of its relations were skipped), by table name
+	cut map[string]map[string]struct{}
+}
+
+// exceeded reports whether the deadline passed. It is false for a nil budget.
+func (b *syncBudget) exceeded() bool {
+	return b != nil && !time.Now().Before(b.deadline)
+}
+
+// skip records that table was not started for the client. If the table is a relation, parent is cut short.
+func (b *syncBudget) skip(table *schema.Table, client schema.ClientMeta, parent *schema.Table) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	addClient(b.skipped, table.Name, client.ID())
+	if parent != nil {
+		addClient(b.cut, parent.Name, client.ID())
+	}
+}
+
+func (b *syncBudget) skipAll(tableClients []tableClient) {
+	for _, tc := range tableClients {
+		b.skip(tc.table, tc.client, nil)
+	}
+}
+
+// incomplete reports whether any table was skipped or cut short. It is false for a nil budget.
+func (b *syncBudget) incomplete() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.skipped) > 0
+}
+
+// syncErrors returns a SyncError for every table that was skipped or cut short, sorted by table name.
+func (b *syncBudget) syncErrors() []*message.SyncError {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msgs := make([]*message.SyncError, 0, len(b.skipped)+len(b.cut))
+	for tableName, clients := range b.skipped {
+		msgs = append(msgs, &message.SyncError{
+			TableName: tableName,
+			Error:     fmt.Sprintf("%s: table skipped for %d client(s)", errSyncDeadlineExceeded, len(clients)),
+		})
+	}
+	for tableName, clients := range b.cut {
+		msgs = append(msgs, &message.SyncError{
+			TableName: tableName,
+			Error:     fmt.Sprintf("%s: table cut short for %d client(s), some relations were not synced", errSyncDeadlineExceeded, len(clients)),
+		})
+	}
+	slices.SortFunc(msgs, func(a, b *message.SyncError) int {
+		return cmp.Or(cmp.Compare(a.TableName, b.TableName), cmp.Compare(a.Error, b.Error))
+	})
+	return msgs
+}
+
+func addClient(m map[string]map[string]struct{}, tableName string, clientID string) {
+	if m[tableName] == nil {
+		m[tableName] = make(map[string]struct{})
+	}
+	m[tableName][clientID] = struct{}{}
+}
+
+// acquireStart acquires a slot of sem to start resolving a table, giving up with errSyncDeadlineExceeded
+// once the sync deadline passes.
+func (s *syncClient) acquireStart(ctx context.Context, sem *semaphore.Weighted) error {
+	if s.budget == nil {
+		return sem.Acquire(ctx, 1)
+	}
+	if s.budget.exceeded() {
+		return errSyncDeadlineExceeded
+	}
+	budgetCtx, cancel := context.WithDeadline(ctx, s.budget.deadline)
+	defer cancel()
+	if err := sem.Acquire(budgetCtx, 1); err != nil {
+		if ctx.Err() == nil {
+			return errSyncDeadlineExceeded
+		}
+		return err
+	}
+	return nil
+}
+
+// reportSkipped sends a SyncError for every table skipped or cut short because the sync deadline passed.
+func (s *syncClient) reportSkipped() {
+	msgs := s.budget.syncErrors()
+	if len(msgs) == 0 {
+		return
+	}
+	s.logger.Warn().Int("tables", len(msgs)).Msg("sync deadline exceeded, some tables were skipped or cut short")
+	for _, msg := range msgs {
+		s.msgChan <- msg
+	}
+}
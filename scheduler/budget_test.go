@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_SyncDeadline(t *testing.T) {
+	slowParent := func(deadline time.Time) *schema.Table {
+		table := testTableRelationSuccess()
+		table.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+			// the resolver is in flight when the deadline passes, so it is left to finish
+			time.Sleep(time.Until(deadline) + 10*time.Millisecond)
+			return testResolverSuccess(ctx, meta, parent, res)
+		}
+		return table
+	}
+
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			cases := []struct {
+				name         string
+				tables       func(deadline time.Time) schema.Tables
+				deadline     time.Duration
+				wantInserted []string
+				wantErrors   []string
+			}{
+				{
+					name:         "relations cut short",
+					tables:       func(deadline time.Time) schema.Tables { return schema.Tables{slowParent(deadline)} },
+					deadline:     100 * time.Millisecond,
+					wantInserted: []string{"test_table_relation_success"},
+					wantErrors: []string{
+						"test_table_relation_success: sync deadline exceeded: table cut short for 1 client(s), some relations were not synced",
+						"test_table_success: sync deadline exceeded: table skipped for 1 client(s)",
+					},
+				},
+				{
+					name: "tables skipped",
+					tables: func(time.Time) schema.Tables {
+						return schema.Tables{testTableSuccess(), testTableSuccessWithPK()}
+					},
+					deadline: -time.Second,
+					wantErrors: []string{
+						"test_table_success: sync deadline exceeded: table skipped for 1 client(s)",
+						"test_table_success_pk: sync deadline exceeded: table skipped for 1 client(s)",
+					},
+				},
+				{
+					name: "deadline not reached",
+					tables: func(time.Time) schema.Tables {
+						return schema.Tables{testTableRelationSuccess()}
+					},
+					deadline:     time.Minute,
+					wantInserted: []string{"test_table_relation_success", "test_table_success"},
+				},
+			}
+			for _, tc := range cases {
+				t.Run(tc.name, func(t *testing.T) {
+					deadline := time.Now().Add(tc.deadline)
+					sc := NewScheduler(
+						WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+						WithStrategy(strategy),
+						WithoutBatching(),
+						WithConcurrency(100),
+					)
+					msgs := make(chan message.SyncMessage, 500)
+					require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tc.tables(deadline), msgs, WithSyncDeadline(deadline)))
+					close(msgs)
+
+					var inserted, syncErrors []string
+					for msg := range msgs {
+						switch m := msg.(type) {
+						case *message.SyncInsert:
+							name, _ := m.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+							inserted = append(inserted, name)
+						case *message.SyncError:
+							syncErrors = append(syncErrors, m.TableName+": "+m.Error)
+						}
+					}
+					require.ElementsMatch(t, tc.wantInserted, inserted)
+					require.Equal(t, tc.wantErrors, syncErrors)
+				})
+			}
+		})
+	}
+}
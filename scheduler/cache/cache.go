@@ -0,0 +1,157 @@
+// Package cache provides a memoization cache that resolvers can use to share expensive API responses
+// within a single sync, across tables, relations and goroutines.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/scheduler/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+type contextKey struct{}
+
+// Cache is a size-bounded, least-recently-used cache with a TTL. Concurrent loads of the same key are merged,
+// so the load function is called once no matter how many resolvers ask for the key at the same time.
+// It is safe for concurrent use.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	metrics    *metrics.Metrics
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru holds the entries, most recently used first
+	lru   *list.List
+	group singleflight.Group
+}
+
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// New returns a cache keeping up to maxEntries values for ttl. A non-positive ttl keeps values until they are evicted
+// and a non-positive maxEntries doesn't bound the number of values. Hits and misses are recorded in m, if it's not nil.
+func New(ttl time.Duration, maxEntries int, m *metrics.Metrics) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		metrics:    m,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// NewContext returns a copy of ctx carrying the cache.
+func NewContext(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the cache carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Cache {
+	c, _ := ctx.Value(contextKey{}).(*Cache)
+	return c
+}
+
+// Memoize returns the value cached for key in the cache carried by ctx, calling load to fill it on a miss.
+// If ctx carries no cache, load is always called. Errors returned by load are not cached.
+func Memoize[T any](ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	c := FromContext(ctx)
+	if c == nil {
+		return load(ctx)
+	}
+	v, err := c.GetOrLoad(ctx, key, func(ctx context.Context) (any, error) {
+		return load(ctx)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// GetOrLoad returns the value cached for key, calling load to fill it on a miss. Errors returned by load are not cached.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, load func(context.Context) (any, error)) (any, error) {
+	if v, ok := c.Get(ctx, key); ok {
+		return v, nil
+	}
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		// another load for the key may have finished between the lookup and this call
+		if v, ok := c.get(key); ok {
+			return v, nil
+		}
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, v)
+		return v, nil
+	})
+	return v, err
+}
+
+// Get returns the value cached for key, recording a hit or a miss.
+func (c *Cache) Get(ctx context.Context, key string) (any, bool) {
+	v, ok := c.get(key)
+	if c.metrics != nil {
+		if ok {
+			c.metrics.AddCacheHits(ctx, 1)
+		} else {
+			c.metrics.AddCacheMisses(ctx, 1)
+		}
+	}
+	return v, ok
+}
+
+func (c *Cache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Set caches value for key, evicting the least recently used value if the cache is full.
+func (c *Cache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&entry{key: key, value: value, expires: expires})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Len returns the number of cached values, including expired values that were not evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/scheduler/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_LRU(t *testing.T) {
+	ctx := context.Background()
+	m := metrics.NewMetrics()
+	c := New(0, 2, m)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, ok := c.Get(ctx, "a")
+	require.True(t, ok)
+	// "b" is the least recently used value, so it is evicted
+	c.Set("c", 3)
+	require.Equal(t, 2, c.Len())
+	_, ok = c.Get(ctx, "b")
+	require.False(t, ok)
+	v, ok := c.Get(ctx, "c")
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+
+	require.EqualValues(t, 2, m.GetCacheHits())
+	require.EqualValues(t, 1, m.GetCacheMisses())
+}
+
+func TestCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	c := New(20*time.Millisecond, 0, nil)
+	c.Set("a", 1)
+	_, ok := c.Get(ctx, "a")
+	require.True(t, ok)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Get(ctx, "a")
+	require.False(t, ok)
+	require.Zero(t, c.Len())
+}
+
+func TestMemoize(t *testing.T) {
+	var loads atomic.Int64
+	load := func(context.Context) (string, error) {
+		loads.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "value", nil
+	}
+
+	// without a cache every call loads the value
+	for range 2 {
+		v, err := Memoize(context.Background(), "key", load)
+		require.NoError(t, err)
+		require.Equal(t, "value", v)
+	}
+	require.EqualValues(t, 2, loads.Load())
+
+	// concurrent calls are merged into a single load
+	loads.Store(0)
+	ctx := NewContext(context.Background(), New(time.Minute, 10, nil))
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := Memoize(ctx, "key", load)
+			require.NoError(t, err)
+			require.Equal(t, "value", v)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, loads.Load())
+
+	// errors are not cached
+	errLoad := errors.New("load failed")
+	_, err := Memoize(ctx, "failing", func(context.Context) (int, error) { return 0, errLoad })
+	require.ErrorIs(t, err, errLoad)
+	v, err := Memoize(ctx, "failing", func(context.Context) (int, error) { return 1, nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/scheduler/cache"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_ResolverCache(t *testing.T) {
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			var loads atomic.Int64
+			memoizedResolver := func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+				_, err := cache.Memoize(ctx, "accounts", func(context.Context) (int, error) {
+					loads.Add(1)
+					return 1, nil
+				})
+				if err != nil {
+					return err
+				}
+				return testResolverSuccess(ctx, meta, parent, res)
+			}
+			table := testTableRelationSuccess()
+			table.Resolver = memoizedResolver
+			table.Relations[0].Resolver = memoizedResolver
+
+			sc := NewScheduler(
+				WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+				WithStrategy(strategy),
+			)
+			msgs := make(chan message.SyncMessage, 500)
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{table}, msgs, WithResolverCache(time.Minute, 100)))
+			close(msgs)
+			require.EqualValues(t, 1, loads.Load())
+		})
+	}
+}
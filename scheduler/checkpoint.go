@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+)
+
+const (
+	checkpointKeyPrefix = "cq_checkpoint:"
+
+	// defaultCheckpointInterval is the interval at which the completed table clients are stored in the state client
+	defaultCheckpointInterval = 5 * time.Second
+)
+
+// StateClient is the subset of state.Client used to persist sync checkpoints.
+type StateClient interface {
+	SetKey(ctx context.Context, key string, value string) error
+	GetKey(ctx context.Context, key string) (string, error)
+	DeleteKey(ctx context.Context, key string) error
+	Flush(ctx context.Context) error
+}
+
+// WithCheckpoint enables checkpointing of top-level (table, client) pairs in the given state client, so that a sync
+// resuming an interrupted one skips the pairs it completed.
+//
+// A pair is completed once it finished resolving (including its relations) without errors, and all of its rows were
+// sent to the sync messages channel. The completed pairs are stored periodically, and once more when the sync is
+// interrupted. Once a sync finishes without being interrupted, all checkpoints are removed so that the next sync starts
+// from scratch.
+//
+// A checkpoint is only as durable as the rows sent before it: the caller must write the messages it received before
+// stopping, as the rows of a skipped pair aren't sent again. Checkpoints are also bound to syncTime, the sync time the
+// rows are written with: pairs are only skipped by a sync with the same sync time, as a destination deleting stale
+// rows would otherwise delete the rows of the skipped pairs. Checkpoints are disabled if syncTime is zero.
+func WithCheckpoint(client StateClient, syncTime time.Time) SyncOption {
+	return func(s *syncClient) {
+		if syncTime.IsZero() {
+			s.logger.Warn().Msg("sync checkpoints are disabled as the sync time is unknown")
+			return
+		}
+		s.checkpoints = &checkpoints{
+			client:   client,
+			syncTime: syncTime.UTC().Format(time.RFC3339Nano),
+			interval: defaultCheckpointInterval,
+			logger:   s.logger,
+			pairs:    make(map[string]*checkpointPair),
+		}
+	}
+}
+
+func checkpointKey(tableName, clientID string) string {
+	return fmt.Sprintf("%s%s:%s", checkpointKeyPrefix, tableName, clientID)
+}
+
+// checkpoints tracks the top-level table clients of a sync until they're completed, and stores them in batches.
+type checkpoints struct {
+	client   StateClient
+	syncTime string
+	interval time.Duration
+	logger   zerolog.Logger
+
+	mu    sync.Mutex
+	pairs map[string]*checkpointPair
+	// ready holds the keys of the completed pairs that weren't stored yet
+	ready []string
+	// keys holds the keys of all the pairs in the sync, including the skipped ones, to be cleared at the end
+	keys []string
+}
+
+type checkpointPair struct {
+	// pending is the number of resources sent by the resolvers of the pair that the batcher didn't send yet
+	pending   int
+	completed bool
+}
+
+func (c *checkpoints) pair(key string) *checkpointPair {
+	p, ok := c.pairs[key]
+	if !ok {
+		p = &checkpointPair{}
+		c.pairs[key] = p
+	}
+	return p
+}
+
+// add counts a resource of the pair sent by a resolver.
+func (c *checkpoints) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pair(key).pending++
+}
+
+// sent acknowledges resources sent by the batcher, given by the keys of their pairs.
+func (c *checkpoints) sent(keys []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		p := c.pair(key)
+		p.pending--
+		if p.completed && p.pending == 0 {
+			c.ready = append(c.ready, key)
+		}
+	}
+}
+
+// complete marks the pair as resolved, it's stored once all of its resources were sent.
+func (c *checkpoints) complete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.pair(key)
+	p.completed = true
+	if p.pending == 0 {
+		c.ready = append(c.ready, key)
+	}
+}
+
+// store writes the checkpoints of the pairs completed since the last call, flushing the state client once.
+func (c *checkpoints) store(ctx context.Context) {
+	c.mu.Lock()
+	ready := c.ready
+	c.ready = nil
+	c.mu.Unlock()
+	if len(ready) == 0 {
+		return
+	}
+	for _, key := range ready {
+		if err := c.client.SetKey(ctx, key, c.syncTime); err != nil {
+			c.logger.Warn().Err(err).Str("key", key).Msg("failed to store sync checkpoint")
+			return
+		}
+	}
+	if err := c.client.Flush(ctx); err != nil {
+		c.logger.Warn().Err(err).Int("checkpoints", len(ready)).Msg("failed to flush sync checkpoints")
+	}
+}
+
+// start stores the completed pairs periodically until the returned function is called.
+func (c *checkpoints) start(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.store(ctx)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// clear removes the checkpoints of all the pairs in this sync.
+func (c *checkpoints) clear(ctx context.Context) error {
+	for _, key := range c.keys {
+		if err := c.client.DeleteKey(ctx, key); err != nil {
+			return fmt.Errorf("failed to clear sync checkpoint %s: %w", key, err)
+		}
+	}
+	if err := c.client.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush sync checkpoints: %w", err)
+	}
+	return nil
+}
+
+// skipCompleted filters out the table clients that were recorded as completed by a previous sync with the same sync
+// time. All the given table clients are remembered so that their checkpoints can be cleared at the end of the sync.
+func (s *syncClient) skipCompleted(ctx context.Context, tableClients []tableClient) []tableClient {
+	if s.checkpoints == nil {
+		return tableClients
+	}
+
+	remaining := make([]tableClient, 0, len(tableClients))
+	for _, tc := range tableClients {
+		key := checkpointKey(tc.table.Name, tc.client.ID())
+		s.checkpoints.keys = append(s.checkpoints.keys, key)
+		value, err := s.checkpoints.client.GetKey(ctx, key)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("table", tc.table.Name).Str("client", tc.client.ID()).Msg("failed to read sync checkpoint, table will be synced")
+		}
+		if err == nil && value == s.checkpoints.syncTime {
+			s.logger.Info().Str("table", tc.table.Name).Str("client", tc.client.ID()).Msg("skipping table completed by a previous sync")
+			continue
+		}
+		remaining = append(remaining, tc)
+	}
+	return remaining
+}
+
+// markCompleted marks a top-level table client as resolved, to be checkpointed once the batcher sent its resources.
+// Table clients are not recorded if the sync was cancelled while resolving them, if they finished after the sync
+// deadline as they may have been cut short, or if their resolvers or relations recorded errors.
+func (s *syncClient) markCompleted(ctx context.Context, table *schema.Table, client schema.ClientMeta) {
+	s.progress.done(table)
+	if s.checkpoints == nil || ctx.Err() != nil || s.budget.exceeded() {
+		return
+	}
+	for _, t := range (schema.Tables{table}).FlattenTables() {
+		selector := s.metrics.NewSelector(client.ID(), t.Name)
+		if s.metrics.GetErrors(selector) > 0 || s.metrics.GetPanics(selector) > 0 {
+			s.logger.Debug().Str("table", table.Name).Str("client", client.ID()).Msg("not storing sync checkpoint of table with errors")
+			return
+		}
+	}
+	s.checkpoints.complete(checkpointKey(table.Name, client.ID()))
+}
+
+// topLevelTable returns the table of the top-level resource the resource descends from.
+func topLevelTable(resource *schema.Resource) *schema.Table {
+	for resource.Parent != nil {
+		resource = resource.Parent
+	}
+	return resource.Table
+}
@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type testStateClient struct {
+	mu        sync.Mutex
+	values    map[string]string
+	completed []string
+	deleted   []string
+	flushes   int
+
+	// stored is closed once the watched key is stored
+	watch  string
+	stored chan struct{}
+
+	// msgs records the number of messages sent when a pair is stored, if set
+	msgs chan message.SyncMessage
+	sent map[string]int
+}
+
+func newTestStateClient() *testStateClient {
+	return &testStateClient{values: make(map[string]string), stored: make(chan struct{})}
+}
+
+func (c *testStateClient) SetKey(_ context.Context, key string, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+	c.completed = append(c.completed, key)
+	if c.msgs != nil {
+		c.sent[key] = len(c.msgs)
+	}
+	if key == c.watch {
+		close(c.stored)
+	}
+	return nil
+}
+
+func (c *testStateClient) GetKey(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key], nil
+}
+
+func (c *testStateClient) DeleteKey(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	c.deleted = append(c.deleted, key)
+	return nil
+}
+
+func (c *testStateClient) Flush(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushes++
+	return nil
+}
+
+func withCheckpointInterval(interval time.Duration) SyncOption {
+	return func(s *syncClient) {
+		s.checkpoints.interval = interval
+	}
+}
+
+var testSyncTime = time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+func TestScheduler_Checkpoint(t *testing.T) {
+	batching := map[string]Option{
+		"batching":         WithBatchOptions(WithBatchTimeout(10 * time.Millisecond)),
+		"without_batching": WithoutBatching(),
+	}
+	for _, strategy := range AllStrategies {
+		for name, batchOption := range batching {
+			t.Run(strategy.String()+"/"+name, func(t *testing.T) {
+				ctx := context.Background()
+				state := newTestStateClient()
+				skippedKey := checkpointKey("test_table_relation_success", "test")
+				syncedKey := checkpointKey("test_table_success_pk", "test")
+				waitingKey := checkpointKey("test_table_waiting", "test")
+				state.values[skippedKey] = testSyncTime.Format(time.RFC3339Nano)
+				state.watch = syncedKey
+				msgs := make(chan message.SyncMessage, 500)
+				state.msgs, state.sent = msgs, make(map[string]int)
+
+				// the waiting table is resolved once the synced table was checkpointed, while the sync is still running
+				waiting := testTableSuccess()
+				waiting.Name = "test_table_waiting"
+				waiting.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+					select {
+					case <-state.stored:
+					case <-time.After(10 * time.Second):
+						return errors.New("synced table wasn't checkpointed")
+					}
+					return testResolverSuccess(ctx, meta, parent, res)
+				}
+
+				sc := NewScheduler(
+					WithLogger(zerolog.New(zerolog.NewTestWriter(t)).Level(zerolog.DebugLevel)),
+					WithStrategy(strategy),
+					batchOption,
+				)
+				tables := schema.Tables{testTableRelationSuccess(), testTableSuccessWithPK(), waiting}
+				require.NoError(t, sc.Sync(ctx, &testExecutionClient{}, tables, msgs,
+					WithCheckpoint(state, testSyncTime),
+					withCheckpointInterval(time.Millisecond),
+				))
+				close(msgs)
+
+				insertedTables := make(map[string]int)
+				lastSynced := 0
+				sent := 0
+				for msg := range msgs {
+					sent++
+					if m, ok := msg.(*message.SyncInsert); ok {
+						name, _ := m.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+						insertedTables[name] += int(m.Record.NumRows())
+						if name != "test_table_waiting" {
+							lastSynced = sent
+						}
+					}
+				}
+				require.Equal(t, map[string]int{"test_table_success_pk": 1, "test_table_waiting": 1}, insertedTables)
+
+				// the synced table is stored once its rows were sent
+				require.Contains(t, state.completed, syncedKey)
+				require.NotContains(t, state.completed, skippedKey)
+				require.GreaterOrEqual(t, state.sent[syncedKey], lastSynced)
+				// all checkpoints are removed at the end of a successful sync
+				slices.Sort(state.deleted)
+				require.Equal(t, []string{skippedKey, syncedKey, waitingKey}, state.deleted)
+				require.Empty(t, state.values)
+				require.Positive(t, state.flushes)
+			})
+		}
+	}
+}
+
+func TestScheduler_CheckpointSyncTime(t *testing.T) {
+	state := newTestStateClient()
+	key := checkpointKey("test_table_success", "test")
+	state.values[key] = testSyncTime.Add(-time.Hour).Format(time.RFC3339Nano)
+
+	sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+	msgs := make(chan message.SyncMessage, 500)
+	require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{testTableSuccess()}, msgs, WithCheckpoint(state, testSyncTime)))
+	close(msgs)
+
+	// a checkpoint stored by a sync with another sync time isn't reused
+	inserts := 0
+	for msg := range msgs {
+		if _, ok := msg.(*message.SyncInsert); ok {
+			inserts++
+		}
+	}
+	require.Equal(t, 1, inserts)
+	require.Empty(t, state.values)
+}
+
+func TestScheduler_CheckpointWithoutSyncTime(t *testing.T) {
+	state := newTestStateClient()
+	key := checkpointKey("test_table_success", "test")
+	state.values[key] = time.Time{}.Format(time.RFC3339Nano)
+
+	sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+	msgs := make(chan message.SyncMessage, 500)
+	require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{testTableSuccess()}, msgs, WithCheckpoint(state, time.Time{})))
+	close(msgs)
+
+	// checkpoints are disabled, so the state isn't used at all
+	require.Empty(t, state.completed)
+	require.Empty(t, state.deleted)
+	require.Contains(t, state.values, key)
+}
+
+func TestScheduler_CheckpointCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	state := newTestStateClient()
+	sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+	msgs := make(chan message.SyncMessage, 500)
+	_ = sc.Sync(ctx, &testExecutionClient{}, schema.Tables{testTableSuccess()}, msgs, WithCheckpoint(state, testSyncTime))
+	close(msgs)
+
+	// nothing is stored or removed when the sync is cancelled
+	require.Empty(t, state.completed)
+	require.Empty(t, state.deleted)
+}
+
+func TestScheduler_CheckpointErrors(t *testing.T) {
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			state := newTestStateClient()
+			state.watch = checkpointKey("test_table_success_pk", "test")
+			table := testTableRelationSuccess()
+			table.Relations[0].Resolver = func(context.Context, schema.ClientMeta, *schema.Resource, chan<- any) error {
+				return errors.New("relation error")
+			}
+			// the sync keeps running until the healthy table was stored
+			waiting := testTableSuccess()
+			waiting.Name = "test_table_waiting"
+			waiting.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+				select {
+				case <-state.stored:
+				case <-time.After(10 * time.Second):
+					return errors.New("healthy table wasn't checkpointed")
+				}
+				return testResolverSuccess(ctx, meta, parent, res)
+			}
+
+			sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))), WithStrategy(strategy), WithoutBatching())
+			msgs := make(chan message.SyncMessage, 500)
+			tables := schema.Tables{table, testTableSuccessWithPK(), waiting}
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs,
+				WithCheckpoint(state, testSyncTime),
+				withCheckpointInterval(time.Millisecond),
+			))
+			close(msgs)
+
+			// the table is synced again by the next sync, as its relation failed
+			require.Contains(t, state.completed, state.watch)
+			require.NotContains(t, state.completed, checkpointKey(table.Name, "test"))
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+type dependencyContextKey struct{}
+
+// DependencyResources returns the resources of the top-level table tableName resolved by the current sync, for the
+// resolvers of tables listing tableName in schema.Table.DependsOn. Resources of all the clients are returned,
+// without their relations. It returns nil if no table of the sync depends on tableName.
+// The resources are released once the last stage with a table depending on tableName finished, so resolvers shouldn't
+// keep them once they return.
+func DependencyResources(ctx context.Context, tableName string) []*schema.Resource {
+	deps, _ := ctx.Value(dependencyContextKey{}).(*dependencies)
+	if deps == nil {
+		return nil
+	}
+	deps.mu.Lock()
+	defer deps.mu.Unlock()
+	return deps.resources[tableName]
+}
+
+// dependencies collects the resources of the tables other tables depend on.
+type dependencies struct {
+	mu        sync.Mutex
+	resources map[string][]*schema.Resource
+}
+
+// newDependencies returns nil if no table depends on another table of the sync.
+func newDependencies(tables schema.Tables) *dependencies {
+	var deps *dependencies
+	for _, table := range tables {
+		for _, name := range table.DependsOn {
+			if tables.GetTopLevel(name) == nil {
+				continue
+			}
+			if deps == nil {
+				deps = &dependencies{resources: make(map[string][]*schema.Resource)}
+			}
+			deps.resources[name] = nil
+		}
+	}
+	return deps
+}
+
+func (d *dependencies) collect(resource *schema.Resource) {
+	if resource.Table.Parent != nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if resources, ok := d.resources[resource.Table.Name]; ok {
+		d.resources[resource.Table.Name] = append(resources, resource)
+	}
+}
+
+// release drops the resources that no table of the remaining stages depends on, as they're not needed anymore.
+func (d *dependencies) release(remaining []schema.Tables) {
+	needed := make(map[string]struct{})
+	for _, stage := range remaining {
+		for _, table := range stage {
+			for _, name := range table.DependsOn {
+				needed[name] = struct{}{}
+			}
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for name := range d.resources {
+		if _, ok := needed[name]; !ok {
+			delete(d.resources, name)
+		}
+	}
+}
+
+// dependencyStages groups the top-level tables in stages, so that the dependencies of every table are in earlier
+// stages. The order of the tables is kept within a stage. Dependencies that are not part of the sync are ignored.
+func dependencyStages(tables schema.Tables) []schema.Tables {
+	indices := make(map[string]int, len(tables))
+	for i, table := range tables {
+		indices[table.Name] = i
+	}
+	// levels holds the stage index + 1 of every table, 0 until it's computed
+	levels := make([]int, len(tables))
+	var level func(i int) int
+	level = func(i int) int {
+		if levels[i] > 0 {
+			return levels[i]
+		}
+		// guards against cycles, which are rejected when the plugin is validated
+		levels[i] = 1
+		l := 1
+		for _, name := range tables[i].DependsOn {
+			if j, ok := indices[name]; ok && j != i {
+				l = max(l, level(j)+1)
+			}
+		}
+		levels[i] = l
+		return l
+	}
+
+	var stages []schema.Tables
+	for i, table := range tables {
+		l := level(i)
+		for len(stages) < l {
+			stages = append(stages, nil)
+		}
+		stages[l-1] = append(stages[l-1], table)
+	}
+	return stages
+}
+
+// syncStages calls run for every dependency stage in order, with s.tables set to the tables of the stage.
+func (s *syncClient) syncStages(resolvedResources chan<- *schema.Resource, run func(resolvedResources chan<- *schema.Resource)) {
+	stages := dependencyStages(s.tables)
+	if len(stages) <= 1 {
+		run(resolvedResources)
+		return
+	}
+
+	tables := s.tables
+	defer func() {
+		s.tables = tables
+	}()
+	for i, stage := range stages {
+		s.tables = stage
+		names := make([]string, len(stage))
+		for j, table := range stage {
+			names[j] = table.Name
+		}
+		s.logger.Info().Int("stage", i+1).Int("stages", len(stages)).Strs("tables", names).Msg("syncing dependency stage")
+		if s.dependencies == nil {
+			run(resolvedResources)
+			continue
+		}
+		// the resources are collected before they're sent, so that they're all available to the next stage
+		stageResources := make(chan *schema.Resource)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for resource := range stageResources {
+				s.dependencies.collect(resource)
+				resolvedResources <- resource
+			}
+		}()
+		run(stageResources)
+		close(stageResources)
+		<-done
+		s.dependencies.release(stages[i+1:])
+	}
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_DependsOn(t *testing.T) {
+	valueColumn := schema.Column{
+		Name: "value",
+		Type: arrow.PrimitiveTypes.Int64,
+		Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+			return resource.Set(c.Name, resource.Item)
+		},
+	}
+
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			var regionsDone atomic.Bool
+			regions := &schema.Table{
+				Name: "test_dependency_regions",
+				Resolver: func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+					defer regionsDone.Store(true)
+					res <- []int64{1, 2, 3}
+					return nil
+				},
+				Columns: []schema.Column{valueColumn},
+			}
+			instances := &schema.Table{
+				Name:      "test_dependency_instances",
+				DependsOn: []string{regions.Name},
+				Resolver: func(ctx context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+					if !regionsDone.Load() {
+						return errors.New("dependency not synced")
+					}
+					var sum int64
+					for _, r := range DependencyResources(ctx, regions.Name) {
+						sum += r.Get("value").Get().(int64)
+					}
+					res <- sum
+					return nil
+				},
+				Columns: []schema.Column{valueColumn},
+			}
+
+			sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))), WithStrategy(strategy))
+			msgs := make(chan message.SyncMessage, 500)
+			// the dependent table is listed first, but synced last
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{instances, regions}, msgs))
+			close(msgs)
+
+			values := make(map[string][]int64)
+			for msg := range msgs {
+				switch m := msg.(type) {
+				case *message.SyncInsert:
+					name, _ := m.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+					values[name] = append(values[name], m.Record.Column(0).(*array.Int64).Int64Values()...)
+				case *message.SyncError:
+					t.Fatalf("unexpected sync error for table %s: %s", m.TableName, m.Error)
+				}
+			}
+			require.ElementsMatch(t, []int64{1, 2, 3}, values[regions.Name])
+			require.Equal(t, []int64{6}, values[instances.Name])
+		})
+	}
+}
+
+func TestDependencyStages(t *testing.T) {
+	tables := schema.Tables{
+		{Name: "c", DependsOn: []string{"b"}},
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a", "not_synced"}},
+		{Name: "d", DependsOn: []string{"a"}},
+		{Name: "e"},
+	}
+	stages := dependencyStages(tables)
+	names := make([][]string, len(stages))
+	for i, stage := range stages {
+		for _, table := range stage {
+			names[i] = append(names[i], table.Name)
+		}
+	}
+	require.Equal(t, [][]string{{"a", "e"}, {"b", "d"}, {"c"}}, names)
+
+	require.Nil(t, newDependencies(schema.Tables{{Name: "a"}, {Name: "b", DependsOn: []string{"not_synced"}}}))
+}
+
+func TestDependencies_Release(t *testing.T) {
+	a, b := &schema.Table{Name: "a"}, &schema.Table{Name: "b", DependsOn: []string{"a"}}
+	c := &schema.Table{Name: "c", DependsOn: []string{"b"}}
+	d := &schema.Table{Name: "d", DependsOn: []string{"a"}}
+	deps := newDependencies(schema.Tables{a, b, c, d})
+	deps.collect(schema.NewResourceData(a, nil, nil))
+	deps.collect(schema.NewResourceData(b, nil, nil))
+
+	// the resources are kept while a later stage depends on them
+	deps.release([]schema.Tables{{b}, {c, d}})
+	require.Len(t, deps.resources["a"], 1)
+	require.Len(t, deps.resources["b"], 1)
+
+	deps.release([]schema.Tables{{c}})
+	require.NotContains(t, deps.resources, "a")
+	require.Len(t, deps.resources["b"], 1)
+
+	// resources of released tables aren't collected anymore
+	deps.collect(schema.NewResourceData(a, nil, nil))
+	require.NotContains(t, deps.resources, "a")
+
+	deps.release(nil)
+	require.Empty(t, deps.resources)
+	ctx := context.WithValue(context.Background(), dependencyContextKey{}, deps)
+	require.Nil(t, DependencyResources(ctx, "b"))
+}
@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/scalar"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+)
+
+const incrementalKeyPrefix = "cq_cursor:"
+
+type incrementalContextKey struct{}
+
+// WithIncrementalState enables cursor tracking for incremental tables, stored in the given state client.
+// For every (table, client) pair of a table with a single incremental key column, the cursor committed by the
+// previous sync is available to resolvers through IncrementalCursor, and the max incremental key value of the
+// resolved resources is committed as the new cursor once the sync finished and all resources were sent.
+// Cursors of pairs that had errors or panics are not committed, so that the next sync fetches the missing data again.
+func WithIncrementalState(client StateClient) SyncOption {
+	return func(s *syncClient) {
+		s.incremental = &incrementalState{
+			client:  client,
+			cursors: make(map[string]incrementalCursor),
+		}
+	}
+}
+
+// IncrementalCursor returns the cursor committed by the previous sync for the table and client: the max value of
+// the table's incremental key column, formatted as a string (timestamps use time.RFC3339Nano).
+// ok is false if there is no cursor, or if the sync wasn't started with WithIncrementalState.
+func IncrementalCursor(ctx context.Context, tableName string, client schema.ClientMeta) (string, bool, error) {
+	st, _ := ctx.Value(incrementalContextKey{}).(*incrementalState)
+	if st == nil {
+		return "", false, nil
+	}
+	cursor, err := st.cursor(ctx, tableName, client.ID())
+	if err != nil {
+		return "", false, err
+	}
+	return cursor.previous, cursor.previous != "", nil
+}
+
+type incrementalCursor struct {
+	tableName string
+	clientID  string
+	// loaded is set once the previous cursor was read from the state client
+	loaded   bool
+	previous string
+	max      scalar.Scalar
+}
+
+type incrementalState struct {
+	client StateClient
+
+	mu      sync.Mutex
+	cursors map[string]incrementalCursor
+}
+
+func incrementalKey(tableName, clientID string) string {
+	return fmt.Sprintf("%s%s:%s", incrementalKeyPrefix, tableName, clientID)
+}
+
+func (st *incrementalState) cursor(ctx context.Context, tableName, clientID string) (incrementalCursor, error) {
+	key := incrementalKey(tableName, clientID)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	cursor := st.cursors[key]
+	if cursor.loaded {
+		return cursor, nil
+	}
+	previous, err := st.client.GetKey(ctx, key)
+	if err != nil {
+		return incrementalCursor{}, fmt.Errorf("failed to read cursor of table %s: %w", tableName, err)
+	}
+	cursor.tableName, cursor.clientID = tableName, clientID
+	cursor.loaded, cursor.previous = true, previous
+	st.cursors[key] = cursor
+	return cursor, nil
+}
+
+// observe tracks the max incremental key value of the resource.
+func (st *incrementalState) observe(resource *schema.Resource, clientID string) {
+	if st == nil {
+		return
+	}
+	keys := resource.Table.IncrementalKeys()
+	if len(keys) != 1 {
+		return
+	}
+	value := resource.Get(keys[0])
+	if value == nil || !value.IsValid() {
+		return
+	}
+	key := incrementalKey(resource.Table.Name, clientID)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	cursor := st.cursors[key]
+	cursor.tableName, cursor.clientID = resource.Table.Name, clientID
+	if cursor.max == nil || cursorGreater(value, cursor.max) {
+		cursor.max = value
+	}
+	st.cursors[key] = cursor
+}
+
+// commit stores the new cursors of the pairs accepted by include, then flushes the state client.
+// Cursors never go backwards: the previous cursor is kept if it's greater than the max observed value, as happens when
+// the resolver doesn't filter the resources it fetches with the cursor.
+func (st *incrementalState) commit(ctx context.Context, logger zerolog.Logger, include func(tableName, clientID string) bool) error {
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	committed := 0
+	for key, cursor := range st.cursors {
+		if cursor.max == nil {
+			continue
+		}
+		if !include(cursor.tableName, cursor.clientID) {
+			logger.Warn().Str("table", cursor.tableName).Str("client", cursor.clientID).Msg("table finished with errors, cursor not committed")
+			continue
+		}
+		if !cursor.loaded {
+			previous, err := st.client.GetKey(ctx, key)
+			if err != nil {
+				return fmt.Errorf("failed to read cursor of table %s: %w", cursor.tableName, err)
+			}
+			cursor.previous = previous
+		}
+		if cursorBehind(cursor.max, cursor.previous) {
+			logger.Debug().Str("table", cursor.tableName).Str("client", cursor.clientID).Str("cursor", cursor.previous).Msg("resources are older than the cursor, cursor not committed")
+			continue
+		}
+		if err := st.client.SetKey(ctx, key, cursorString(cursor.max)); err != nil {
+			return fmt.Errorf("failed to store cursor of table %s: %w", cursor.tableName, err)
+		}
+		committed++
+	}
+	if committed == 0 {
+		return nil
+	}
+	if err := st.client.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush cursors: %w", err)
+	}
+	return nil
+}
+
+// cursorBehind reports whether the previous cursor is greater than the observed max value. A previous cursor that
+// can't be parsed as the type of the incremental key is ignored.
+func cursorBehind(observed scalar.Scalar, previous string) bool {
+	if previous == "" {
+		return false
+	}
+	prev := scalar.NewScalar(observed.DataType())
+	if err := prev.Set(previous); err != nil {
+		return false
+	}
+	return cursorGreater(prev, observed)
+}
+
+func cursorGreater(a, b scalar.Scalar) bool {
+	switch av := a.(type) {
+	case *scalar.Timestamp:
+		if bv, ok := b.(*scalar.Timestamp); ok {
+			return av.Value.After(bv.Value)
+		}
+	case *scalar.Int:
+		if bv, ok := b.(*scalar.Int); ok {
+			return av.Value > bv.Value
+		}
+	case *scalar.Uint:
+		if bv, ok := b.(*scalar.Uint); ok {
+			return av.Value > bv.Value
+		}
+	case *scalar.Float:
+		if bv, ok := b.(*scalar.Float); ok {
+			return av.Value > bv.Value
+		}
+	}
+	return a.String() > b.String()
+}
+
+func cursorString(s scalar.Scalar) string {
+	if ts, ok := s.(*scalar.Timestamp); ok {
+		return ts.Value.UTC().Format(time.RFC3339Nano)
+	}
+	return s.String()
+}
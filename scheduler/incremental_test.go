@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/scalar"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func testIncrementalTable(values []int64, failAfter bool) *schema.Table {
+	return &schema.Table{
+		Name:          "test_table_incremental",
+		IsIncremental: true,
+		Resolver: func(ctx context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+			cursor, ok, err := IncrementalCursor(ctx, "test_table_incremental", meta)
+			if err != nil {
+				return err
+			}
+			var from int64
+			if ok {
+				if from, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+					return err
+				}
+			}
+			for _, v := range values {
+				if v > from {
+					res <- map[string]any{"UpdatedAt": v}
+				}
+			}
+			if failAfter {
+				return errors.New("failed to list all resources")
+			}
+			return nil
+		},
+		Columns: []schema.Column{
+			{
+				Name:           "updated_at",
+				Type:           arrow.PrimitiveTypes.Int64,
+				IncrementalKey: true,
+			},
+		},
+	}
+}
+
+func TestScheduler_IncrementalState(t *testing.T) {
+	const key = incrementalKeyPrefix + "test_table_incremental:test"
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			state := newTestStateClient()
+			runSync := func(table *schema.Table) (int64, error) {
+				sc := NewScheduler(
+					WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+					WithStrategy(strategy),
+				)
+				msgs := make(chan message.SyncMessage, 500)
+				err := sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{table}, msgs, WithIncrementalState(state))
+				close(msgs)
+				return message.SyncMessages(drain(msgs)).InsertItems(), err
+			}
+
+			// the first sync fetches everything and commits the max value as the cursor
+			inserted, err := runSync(testIncrementalTable([]int64{3, 10, 7}, false))
+			require.NoError(t, err)
+			require.EqualValues(t, 3, inserted)
+			require.Equal(t, "10", state.values[key])
+
+			// the next sync only fetches newer values, but fails so the cursor is not committed
+			inserted, err = runSync(testIncrementalTable([]int64{3, 10, 12, 11}, true))
+			require.NoError(t, err)
+			require.EqualValues(t, 2, inserted)
+			require.Equal(t, "10", state.values[key])
+
+			inserted, err = runSync(testIncrementalTable([]int64{3, 10, 12, 11}, false))
+			require.NoError(t, err)
+			require.EqualValues(t, 2, inserted)
+			require.Equal(t, "12", state.values[key])
+
+			// the cursor doesn't go backwards if the resolver sends resources older than the cursor
+			table := testIncrementalTable([]int64{5}, false)
+			table.Resolver = func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+				res <- map[string]any{"UpdatedAt": int64(5)}
+				return nil
+			}
+			inserted, err = runSync(table)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, inserted)
+			require.Equal(t, "12", state.values[key])
+		})
+	}
+}
+
+func drain(msgs <-chan message.SyncMessage) []message.SyncMessage {
+	var all []message.SyncMessage
+	for msg := range msgs {
+		all = append(all, msg)
+	}
+	return all
+}
+
+func TestCursorBehind(t *testing.T) {
+	ts := &scalar.Timestamp{Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true, Type: arrow.FixedWidthTypes.Timestamp_us.(*arrow.TimestampType)}
+	require.False(t, cursorBehind(ts, ""))
+	require.False(t, cursorBehind(ts, "2024-01-02T03:04:05Z"))
+	require.False(t, cursorBehind(ts, "2024-01-01T00:00:00Z"))
+	require.True(t, cursorBehind(ts, "2024-01-03T00:00:00.5Z"))
+
+	// cursors that can't be parsed are ignored
+	require.False(t, cursorBehind(&scalar.Int{Value: 1, Valid: true}, "not a number"))
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// memoryFlushInterval is the interval at which the pending batches are flushed while a resolver waits for the memory
+// budget, as the resources on their way to the batcher may hold it too
+const memoryFlushInterval = 100 * time.Millisecond
+
+// inFlight tracks the resources sent by the resolvers until the batcher sent their rows, so that the memory budget
+// covers the resources that weren't sent yet, and a top-level table client is only checkpointed once all of its rows
+// were sent. A nil inFlight tracks nothing.
+type inFlight struct {
+	checkpoints *checkpoints
+	memory      *memoryBudget
+	// flush asks the batcher to send its pending batches, nil if resources aren't batched
+	flush func()
+
+	mu        sync.Mutex
+	resources map[*schema.Resource]inFlightTicket
+}
+
+// inFlightTicket is what a resource holds until its row is sent.
+type inFlightTicket struct {
+	// checkpoint is the checkpoint key of the top-level table client of the resource, if checkpoints are enabled
+	checkpoint string
+	// size is the memory budget held by the resource
+	size int64
+}
+
+func newInFlight(checkpoints *checkpoints, memory *memoryBudget) *inFlight {
+	if checkpoints == nil && memory == nil {
+		return nil
+	}
+	return &inFlight{
+		checkpoints: checkpoints,
+		memory:      memory,
+		resources:   make(map[*schema.Resource]inFlightTicket),
+	}
+}
+
+// add tracks the resource sent by a resolver of the client, blocking until the memory budget has room for it. If ctx
+// is done first the resource is tracked without using the budget, so that it's still sent.
+func (f *inFlight) add(ctx context.Context, resource *schema.Resource, client schema.ClientMeta) {
+	if f == nil {
+		return
+	}
+	var ticket inFlightTicket
+	if f.memory != nil {
+		ticket.size = estimateResourceSize(resource)
+		if !f.acquire(ctx, ticket.size) {
+			ticket.size = 0
+		}
+	}
+	if f.checkpoints != nil {
+		ticket.checkpoint = checkpointKey(topLevelTable(resource).Name, client.ID())
+		f.checkpoints.add(ticket.checkpoint)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resources[resource] = ticket
+}
+
+// acquire reserves size bytes of the memory budget, flushing the pending batches while it waits.
+func (f *inFlight) acquire(ctx context.Context, size int64) bool {
+	for !f.memory.tryAcquire(size) {
+		if f.flush == nil {
+			return f.memory.acquire(ctx, size) == nil
+		}
+		// send the buffered rows to free up the budget instead of waiting for the batch timeout
+		f.flush()
+		waitCtx, cancel := context.WithTimeout(ctx, memoryFlushInterval)
+		err := f.memory.acquire(waitCtx, size)
+		cancel()
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// take stops tracking the resource once the batcher holds its row, returning the ticket to release once it's sent.
+func (f *inFlight) take(resource *schema.Resource) inFlightTicket {
+	if f == nil {
+		return inFlightTicket{}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ticket := f.resources[resource]
+	delete(f.resources, resource)
+	return ticket
+}
+
+// sent releases the tickets of the resources whose rows were sent.
+func (f *inFlight) sent(tickets ...inFlightTicket) {
+	if f == nil || len(tickets) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		f.memory.release(ticket.size)
+		if ticket.checkpoint != "" {
+			keys = append(keys, ticket.checkpoint)
+		}
+	}
+	if f.checkpoints != nil {
+		f.checkpoints.sent(keys)
+	}
+}
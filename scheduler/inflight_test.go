@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func testInFlightResource(size int) *schema.Resource {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "data", Type: arrow.BinaryTypes.String}}}
+	resource := schema.NewResourceData(table, nil, nil)
+	if err := resource.Set("data", strings.Repeat("a", size)); err != nil {
+		panic(err)
+	}
+	return resource
+}
+
+func TestInFlight_MemoryBudget(t *testing.T) {
+	ctx := context.Background()
+	f := newInFlight(nil, newMemoryBudget(100))
+	first, second := testInFlightResource(60), testInFlightResource(60)
+	f.add(ctx, first, &testExecutionClient{})
+
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		f.add(ctx, second, &testExecutionClient{})
+	}()
+	select {
+	case <-added:
+		t.Fatal("resource added over the memory budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the budget is held until the resource is sent, not when the batcher takes it
+	ticket := f.take(first)
+	require.Equal(t, int64(60), ticket.size)
+	select {
+	case <-added:
+		t.Fatal("resource added before the first one was sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+	f.sent(ticket)
+	select {
+	case <-added:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resource not added once the first one was sent")
+	}
+	f.sent(f.take(second))
+}
+
+func TestInFlight_MemoryBudgetFlush(t *testing.T) {
+	ctx := context.Background()
+	f := newInFlight(nil, newMemoryBudget(100))
+	first := testInFlightResource(60)
+	flushes := 0
+	f.flush = func() {
+		flushes++
+		if flushes == 3 {
+			f.sent(f.take(first))
+		}
+	}
+	f.add(ctx, first, &testExecutionClient{})
+
+	// pending batches are flushed until the budget is freed up
+	f.add(ctx, testInFlightResource(60), &testExecutionClient{})
+	require.Equal(t, 3, flushes)
+}
+
+func TestInFlight_MemoryBudgetCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := newInFlight(nil, newMemoryBudget(100))
+	f.add(ctx, testInFlightResource(60), &testExecutionClient{})
+	cancel()
+
+	// the resource is still tracked, without holding the budget
+	resource := testInFlightResource(60)
+	f.add(ctx, resource, &testExecutionClient{})
+	require.Equal(t, inFlightTicket{}, f.take(resource))
+}
+
+func TestInFlight_Nil(t *testing.T) {
+	require.Nil(t, newInFlight(nil, nil))
+
+	var f *inFlight
+	resource := testInFlightResource(1)
+	f.add(context.Background(), resource, &testExecutionClient{})
+	require.Equal(t, inFlightTicket{}, f.take(resource))
+	f.sent(inFlightTicket{})
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/cloudquery/plugin-sdk/v4/scalar"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"golang.org/x/sync/semaphore"
+)
+
+// fixedScalarSize is the estimated size of fixed-width and null values
+const fixedScalarSize = 16
+
+// WithMemoryBudget limits the estimated size in bytes of the resolved resources held by the scheduler, that is the
+// resources sent by the resolvers that were not sent on the sync messages channel yet, including the ones buffered in
+// batches. Once the budget is used up, pending batches are flushed and resolvers block when sending new resources until
+// resources are sent. A single resource larger than the budget uses the whole budget.
+// Defaults to 0 (no limit).
+func WithMemoryBudget(bytes int64) Option {
+	return func(s *Scheduler) {
+		s.memoryBudget = bytes
+	}
+}
+
+// memoryBudget is a weighted semaphore of bytes. A nil memoryBudget has no limit.
+type memoryBudget struct {
+	size int64
+	sem  *semaphore.Weighted
+}
+
+func newMemoryBudget(size int64) *memoryBudget {
+	if size <= 0 {
+		return nil
+	}
+	return &memoryBudget{size: size, sem: semaphore.NewWeighted(size)}
+}
+
+// weight caps n to the budget size, so that large resources don't block forever.
+func (m *memoryBudget) weight(n int64) int64 {
+	return min(n, m.size)
+}
+
+// tryAcquire reserves n bytes without blocking, reporting whether it succeeded.
+func (m *memoryBudget) tryAcquire(n int64) bool {
+	if m == nil {
+		return true
+	}
+	return m.sem.TryAcquire(m.weight(n))
+}
+
+// acquire reserves n bytes, blocking until they're released or ctx is done.
+func (m *memoryBudget) acquire(ctx context.Context, n int64) error {
+	if m == nil {
+		return nil
+	}
+	return m.sem.Acquire(ctx, m.weight(n))
+}
+
+// release returns n bytes reserved with acquire or tryAcquire.
+func (m *memoryBudget) release(n int64) {
+	if m == nil || n == 0 {
+		return
+	}
+	m.sem.Release(m.weight(n))
+}
+
+// estimateResourceSize returns the estimated size in bytes of the resource values.
+func estimateResourceSize(resource *schema.Resource) int64 {
+	var size int64
+	for _, v := range resource.GetValues() {
+		size += estimateScalarSize(v)
+	}
+	return size
+}
+
+func estimateScalarSize(s scalar.Scalar) int64 {
+	if s == nil || !s.IsValid() {
+		return fixedScalarSize
+	}
+	switch v := s.(type) {
+	case *scalar.String:
+		return int64(len(v.Value))
+	case *scalar.LargeString:
+		return int64(len(v.String()))
+	case *scalar.Binary:
+		return int64(len(v.Value))
+	case *scalar.LargeBinary:
+		return int64(len(v.Value))
+	case *scalar.JSON:
+		return int64(len(v.Value))
+	case *scalar.Struct:
+		return int64(len(v.String()))
+	case *scalar.List:
+		var size int64
+		for _, e := range v.Value {
+			size += estimateScalarSize(e)
+		}
+		return size
+	default:
+		return fixedScalarSize
+	}
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_MemoryBudget(t *testing.T) {
+	const items, itemSize = 100, 1024
+	table := &schema.Table{
+		Name: "test_table_large_resources",
+		Resolver: func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+			for i := 0; i < items; i++ {
+				res <- strings.Repeat("a", itemSize)
+			}
+			return nil
+		},
+		Columns: []schema.Column{
+			{
+				Name: "data",
+				Type: arrow.BinaryTypes.String,
+				Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+					return resource.Set(c.Name, resource.Item)
+				},
+			},
+		},
+	}
+
+	batching := map[string]Option{
+		// batches would only be sent on timeout without the budget
+		"batching":         WithBatchOptions(WithBatchMaxRows(items*2), WithBatchTimeout(time.Minute)),
+		"without_batching": WithoutBatching(),
+	}
+	for _, strategy := range AllStrategies {
+		for name, batchOption := range batching {
+			t.Run(strategy.String()+"/"+name, func(t *testing.T) {
+				sc := NewScheduler(
+					WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+					WithStrategy(strategy),
+					batchOption,
+					WithMemoryBudget(4*itemSize),
+				)
+				msgs := make(chan message.SyncMessage, 500)
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				require.NoError(t, sc.Sync(ctx, &testExecutionClient{}, schema.Tables{table}, msgs))
+				close(msgs)
+
+				var rows int64
+				for msg := range msgs {
+					if insert, ok := msg.(*message.SyncInsert); ok {
+						// a batch can't hold more resources than fit in the budget
+						require.LessOrEqual(t, insert.Record.NumRows(), int64(4))
+						rows += insert.Record.NumRows()
+					}
+				}
+				require.Equal(t, int64(items), rows)
+			})
+		}
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	ctx := context.Background()
+	m := newMemoryBudget(100)
+	require.True(t, m.tryAcquire(60))
+	require.False(t, m.tryAcquire(60))
+	m.release(60)
+
+	// resources larger than the budget use the whole budget
+	require.NoError(t, m.acquire(ctx, 1000))
+	require.False(t, m.tryAcquire(1))
+	m.release(1000)
+	require.True(t, m.tryAcquire(100))
+
+	// a nil budget has no limit
+	var unlimited *memoryBudget
+	require.True(t, unlimited.tryAcquire(1<<40))
+	require.NoError(t, unlimited.acquire(ctx, 1<<40))
+	unlimited.release(1 << 40)
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,15 +19,23 @@ const (
 	resourcesMetricName = "sync.table.resources"
 	errorsMetricName    = "sync.table.errors"
 	panicsMetricName    = "sync.table.panics"
+	retriesMetricName   = "sync.table.retries"
 	durationMetricName  = "sync.table.duration"
+
+	cacheHitsMetricName   = "sync.cache.hits"
+	cacheMissesMetricName = "sync.cache.misses"
 )
 
 var (
 	resources metric.Int64Counter
 	errors    metric.Int64Counter
 	panics    metric.Int64Counter
+	retries   metric.Int64Counter
 	duration  metric.Int64Counter
-	once      sync.Once
+
+	cacheHits   metric.Int64Counter
+	cacheMisses metric.Int64Counter
+	once        sync.Once
 )
 
 func NewMetrics() *Metrics {
@@ -46,18 +55,37 @@ func NewMetrics() *Metrics {
 			metric.WithUnit("/{tot}"),
 		)
 
+		retries, _ = otel.Meter(ResourceName).Int64Counter(retriesMetricName,
+			metric.WithDescription("Number of resolver calls retried while syncing a table"),
+			metric.WithUnit("/{tot}"),
+		)
+
 		duration, _ = otel.Meter(ResourceName).Int64Counter(durationMetricName,
 			metric.WithDescription("Duration of syncing a table"),
 			metric.WithUnit("ms"),
 		)
+
+		cacheHits, _ = otel.Meter(ResourceName).Int64Counter(cacheHitsMetricName,
+			metric.WithDescription("Number of resolver cache hits"),
+			metric.WithUnit("/{tot}"),
+		)
+
+		cacheMisses, _ = otel.Meter(ResourceName).Int64Counter(cacheMissesMetricName,
+			metric.WithDescription("Number of resolver cache misses"),
+			metric.WithUnit("/{tot}"),
+		)
 	})
 
 	return &Metrics{
 		resources: resources,
 		errors:    errors,
 		panics:    panics,
+		retries:   retries,
 		duration:  duration,
 
+		cacheHitsCounter:   cacheHits,
+		cacheMissesCounter: cacheMisses,
+
 		measurements: make(map[string]tableMeasurements),
 	}
 }
@@ -66,8 +94,14 @@ type Metrics struct {
 	resources metric.Int64Counter
 	errors    metric.Int64Counter
 	panics    metric.Int64Counter
+	retries   metric.Int64Counter
 	duration  metric.Int64Counter
 
+	cacheHitsCounter   metric.Int64Counter
+	cacheMissesCounter metric.Int64Counter
+	cacheHits          uint64
+	cacheMisses        uint64
+
 	measurements map[string]tableMeasurements
 }
 
@@ -80,9 +114,16 @@ type measurement struct {
 	resources uint64
 	errors    uint64
 	panics    uint64
+	retries   uint64
 	duration  *durationMeasurement
+
+	samplesLock  sync.Mutex
+	errorSamples []string
 }
 
+// MaxErrorSamples is the maximum number of error messages kept for every table and client pair.
+const MaxErrorSamples = 5
+
 func (*Metrics) NewSelector(clientID, tableName string) Selector {
 	return Selector{
 		Set: attribute.NewSet(
@@ -129,6 +170,16 @@ func (m *Metrics) TotalPanics() uint64 {
 	return total
 }
 
+func (m *Metrics) TotalRetries() uint64 {
+	var total uint64
+	for _, clientMetrics := range m.measurements {
+		for _, metrics := range clientMetrics.clients {
+			total += atomic.LoadUint64(&metrics.retries)
+		}
+	}
+	return total
+}
+
 // Deprecated: Use TotalPanics instead, it provides the same functionality but is more consistent with the naming of other metrics methods.
 func (m *Metrics) TotalPanicsAtomic() uint64 {
 	return m.TotalPanics()
@@ -168,6 +219,35 @@ func (m *Metrics) AddErrors(ctx context.Context, count int64, selector Selector)
 	atomic.AddUint64(&m.measurements[selector.tableName].clients[selector.clientID].errors, uint64(count))
 }
 
+// AddError adds a single error, keeping its message as a sample if fewer than MaxErrorSamples were kept so far.
+func (m *Metrics) AddError(ctx context.Context, err error, selector Selector) {
+	m.AddErrors(ctx, 1, selector)
+	tc := m.measurements[selector.tableName].clients[selector.clientID]
+	tc.samplesLock.Lock()
+	defer tc.samplesLock.Unlock()
+	if len(tc.errorSamples) < MaxErrorSamples {
+		tc.errorSamples = append(tc.errorSamples, err.Error())
+	}
+}
+
+// GetErrorSamples returns the error messages kept by AddError.
+func (m *Metrics) GetErrorSamples(selector Selector) []string {
+	tc := m.measurements[selector.tableName].clients[selector.clientID]
+	tc.samplesLock.Lock()
+	defer tc.samplesLock.Unlock()
+	return slices.Clone(tc.errorSamples)
+}
+
+// ClientIDs returns the sorted IDs of the clients the table was initialized with.
+func (m *Metrics) ClientIDs(tableName string) []string {
+	ids := make([]string, 0, len(m.measurements[tableName].clients))
+	for id := range m.measurements[tableName].clients {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 func (m *Metrics) GetErrors(selector Selector) uint64 {
 	return atomic.LoadUint64(&m.measurements[selector.tableName].clients[selector.clientID].errors)
 }
@@ -181,6 +261,33 @@ func (m *Metrics) GetPanics(selector Selector) uint64 {
 	return atomic.LoadUint64(&m.measurements[selector.tableName].clients[selector.clientID].panics)
 }
 
+func (m *Metrics) AddRetries(ctx context.Context, count int64, selector Selector) {
+	m.retries.Add(ctx, count, metric.WithAttributeSet(selector.Set))
+	atomic.AddUint64(&m.measurements[selector.tableName].clients[selector.clientID].retries, uint64(count))
+}
+
+func (m *Metrics) GetRetries(selector Selector) uint64 {
+	return atomic.LoadUint64(&m.measurements[selector.tableName].clients[selector.clientID].retries)
+}
+
+func (m *Metrics) AddCacheHits(ctx context.Context, count int64) {
+	m.cacheHitsCounter.Add(ctx, count)
+	atomic.AddUint64(&m.cacheHits, uint64(count))
+}
+
+func (m *Metrics) GetCacheHits() uint64 {
+	return atomic.LoadUint64(&m.cacheHits)
+}
+
+func (m *Metrics) AddCacheMisses(ctx context.Context, count int64) {
+	m.cacheMissesCounter.Add(ctx, count)
+	atomic.AddUint64(&m.cacheMisses, uint64(count))
+}
+
+func (m *Metrics) GetCacheMisses() uint64 {
+	return atomic.LoadUint64(&m.cacheMisses)
+}
+
 func (m *Metrics) StartTime(start time.Time, selector Selector) {
 	t := m.measurements[selector.tableName]
 	tc := t.clients[selector.clientID]
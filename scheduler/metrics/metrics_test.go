@@ -1,9 +1,11 @@
 package metrics
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,3 +126,26 @@ func TestMetrics(t *testing.T) {
 	// This should work because the 2 metrics are built sequentially; in practice though, this is probably not the case.
 	require.GreaterOrEqual(t, m.TableDuration(s1.tableName), m.GetDuration(s1)+m.GetDuration(s2))
 }
+
+func TestMetrics_ErrorSamples(t *testing.T) {
+	m := NewMetrics()
+	m.InitWithClients(&schema.Table{Name: "test_table"}, []schema.ClientMeta{testClient("b"), testClient("a")})
+	require.Equal(t, []string{"a", "b"}, m.ClientIDs("test_table"))
+	require.Empty(t, m.ClientIDs("missing_table"))
+
+	s := m.NewSelector("a", "test_table")
+	for i := range MaxErrorSamples + 2 {
+		m.AddError(t.Context(), fmt.Errorf("error %d", i), s)
+	}
+	require.EqualValues(t, MaxErrorSamples+2, m.GetErrors(s))
+	samples := m.GetErrorSamples(s)
+	require.Len(t, samples, MaxErrorSamples)
+	require.Equal(t, "error 0", samples[0])
+	require.Empty(t, m.GetErrorSamples(m.NewSelector("b", "test_table")))
+}
+
+type testClient string
+
+func (c testClient) ID() string {
+	return string(c)
+}
@@ -8,3 +8,11 @@ type Selector struct {
 	clientID  string
 	tableName string
 }
+
+func (s Selector) ClientID() string {
+	return s.clientID
+}
+
+func (s Selector) TableName() string {
+	return s.tableName
+}
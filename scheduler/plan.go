@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/helpers"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// WithPlan runs the sync in plan mode: multiplexers are expanded and table resolvers are called for the
+// top-level tables and for relations up to maxDepth (1 means top-level tables only), but no other resolvers run
+// and no migrate or insert messages are sent. Instead, a message.SyncSummary with Planned set is sent, holding
+// the planned (table, client) pairs along with the number of items returned by their table resolvers.
+// Relation table resolvers only get parent resources holding the item returned by the parent table resolver.
+func WithPlan(maxDepth int) SyncOption {
+	return func(s *syncClient) {
+		s.planDepth = max(maxDepth, 1)
+	}
+}
+
+func (s *syncClient) syncPlan(ctx context.Context) {
+	tableClients := make([]tableClient, 0)
+	for _, table := range s.tables {
+		clients := []schema.ClientMeta{s.client}
+		if table.Multiplex != nil {
+			clients = table.Multiplex(s.client)
+		}
+		s.metrics.InitWithClients(table, clients)
+		for _, client := range clients {
+			tableClients = append(tableClients, tableClient{table: table, client: client})
+		}
+	}
+	tableClients = shardTableClients(tableClients, s.shard)
+
+	var wg sync.WaitGroup
+	for _, tc := range tableClients {
+		if err := s.scheduler.tableSems[0].Acquire(ctx, 1); err != nil {
+			// This means context was cancelled
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.scheduler.tableSems[0].Release(1)
+			s.planTable(ctx, tc.table, tc.client, nil, 1)
+		}()
+	}
+	wg.Wait()
+
+	summary := s.syncSummary()
+	summary.Planned = true
+	// relations deeper than the plan depth are initialized along with their tables, but never planned
+	planned := plannedTableNames(s.tables, s.planDepth)
+	summary.Tables = slices.DeleteFunc(summary.Tables, func(table message.TableSyncSummary) bool {
+		return !planned[table.TableName]
+	})
+	select {
+	case s.msgChan <- summary:
+	case <-ctx.Done():
+	}
+}
+
+// planTable calls the table resolver, counting the items it returns, then plans the relations of every item
+// unless depth reached the plan depth.
+func (s *syncClient) planTable(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, depth int) {
+	clientID := client.ID()
+	logger := s.logger.With().Str("table", table.Name).Str("client", clientID).Logger()
+	selector := s.metrics.NewSelector(clientID, table.Name)
+	s.metrics.StartTime(time.Now(), selector)
+	defer func() {
+		s.metrics.EndTime(ctx, time.Now(), selector)
+	}()
+
+	res := make(chan any)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				logger.Error().Interface("error", err).Str("stack", fmt.Sprintf("%s\n%s", err, string(debug.Stack()))).Msg("table resolver finished with panic")
+				s.metrics.AddPanics(ctx, 1, selector)
+			}
+			close(res)
+		}()
+		if err := s.scheduler.waitRateLimit(ctx, clientID, table.Name); err != nil {
+			return
+		}
+		if err := table.Resolver(ctx, client, parent, res); err != nil {
+			event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhaseTableResolver}
+			if s.scheduler.errorClassifier.Suppress(ctx, err, event) {
+				logger.Debug().Err(err).Msg("table resolver finished with suppressed error")
+				return
+			}
+			logger.Error().Err(err).Msg("table resolver finished with error")
+			s.metrics.AddError(ctx, err, selector)
+			s.msgChan <- &message.SyncError{
+				TableName: table.Name,
+				Error:     err.Error(),
+			}
+		}
+	}()
+
+	var items []any
+	for r := range res {
+		resources := helpers.InterfaceSlice(r)
+		s.metrics.AddResources(ctx, int64(len(resources)), selector)
+		if depth < s.planDepth && len(table.Relations) > 0 {
+			items = append(items, resources...)
+		}
+	}
+
+	for _, item := range items {
+		resource := schema.NewResourceData(table, parent, item)
+		for _, relation := range table.Relations {
+			s.planTable(ctx, relation, client, resource, depth+1)
+		}
+	}
+}
+
+// plannedTableNames returns the names of the tables and relations up to maxDepth.
+func plannedTableNames(tables schema.Tables, maxDepth int) map[string]bool {
+	names := make(map[string]bool)
+	var add func(tables schema.Tables, depth int)
+	add = func(tables schema.Tables, depth int) {
+		for _, table := range tables {
+			names[table.Name] = true
+			if depth < maxDepth {
+				add(table.Relations, depth+1)
+			}
+		}
+	}
+	add(tables, 1)
+	return names
+}
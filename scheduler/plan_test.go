@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_Plan(t *testing.T) {
+	cases := []struct {
+		name      string
+		depth     int
+		resources map[string]uint64
+	}{
+		{
+			name:  "top-level tables",
+			depth: 1,
+			// relations aren't planned, so they're left out of the plan
+			resources: map[string]uint64{
+				"test_table_relation_success":      1,
+				"test_table_column_resolver_panic": 1,
+			},
+		},
+		{
+			name:  "relations",
+			depth: 2,
+			resources: map[string]uint64{
+				"test_table_relation_success":      1,
+				"test_table_success":               1,
+				"test_table_column_resolver_panic": 1,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+			msgs := make(chan message.SyncMessage, 500)
+			tables := schema.Tables{testTableRelationSuccess(), testTableColumnResolverPanic()}
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, WithPlan(tc.depth)))
+			close(msgs)
+
+			var all []message.SyncMessage
+			for msg := range msgs {
+				all = append(all, msg)
+			}
+			// only the plan is sent, without any migrate or insert messages
+			require.Len(t, all, 1)
+			summary, ok := all[0].(*message.SyncSummary)
+			require.True(t, ok)
+			require.True(t, summary.Planned)
+
+			resources := make(map[string]uint64)
+			for _, table := range summary.Tables {
+				require.Len(t, table.Clients, 1)
+				// column resolvers are not called in plan mode
+				require.Zero(t, table.Clients[0].Panics)
+				resources[table.TableName] = table.Clients[0].Resources
+			}
+			require.Equal(t, tc.resources, resources)
+		})
+	}
+}
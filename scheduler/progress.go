@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// WithSyncProgress makes the scheduler send a message.SyncProgress every interval while tables are resolved.
+// It's disabled if interval isn't positive.
+func WithSyncProgress(interval time.Duration) SyncOption {
+	return func(s *syncClient) {
+		if interval <= 0 {
+			s.progress = nil
+			return
+		}
+		s.progress = &syncProgress{interval: interval, inProgress: make(map[string]int)}
+	}
+}
+
+// syncProgress tracks the top-level table clients of a sync. A nil syncProgress tracks nothing.
+type syncProgress struct {
+	interval time.Duration
+	start    time.Time
+
+	planned   atomic.Uint64
+	completed atomic.Uint64
+	resources atomic.Uint64
+
+	mu sync.Mutex
+	// inProgress holds the number of clients every top-level table is being resolved for, by table name
+	inProgress map[string]int
+}
+
+// plan adds table clients to the planned work.
+func (p *syncProgress) plan(tableClients []tableClient) {
+	if p != nil {
+		p.planned.Add(uint64(len(tableClients)))
+	}
+}
+
+// started records that a top-level table started being resolved for a client.
+func (p *syncProgress) started(table *schema.Table) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inProgress[table.Name]++
+}
+
+// done records that a top-level table client finished or was skipped.
+func (p *syncProgress) done(table *schema.Table) {
+	if p == nil {
+		return
+	}
+	p.completed.Add(1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inProgress[table.Name] <= 1 {
+		delete(p.inProgress, table.Name)
+		return
+	}
+	p.inProgress[table.Name]--
+}
+
+// resolved counts a resource sent by the scheduler.
+func (p *syncProgress) resolved() {
+	if p != nil {
+		p.resources.Add(1)
+	}
+}
+
+func (p *syncProgress) message() *message.SyncProgress {
+	p.mu.Lock()
+	tables := make([]string, 0, len(p.inProgress))
+	for name := range p.inProgress {
+		tables = append(tables, name)
+	}
+	p.mu.Unlock()
+	slices.Sort(tables)
+	return &message.SyncProgress{
+		TablesInProgress: tables,
+		Resources:        p.resources.Load(),
+		CompletedWork:    p.completed.Load(),
+		PlannedWork:      p.planned.Load(),
+		Elapsed:          time.Since(p.start),
+	}
+}
+
+// report sends a progress message every interval until stop is closed or ctx is done.
+func (p *syncProgress) report(ctx context.Context, res chan<- message.SyncMessage, stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case res <- p.message():
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startProgress starts reporting the progress of the sync, returning a function that stops it.
+func (s *syncClient) startProgress(ctx context.Context) func() {
+	if s.progress == nil {
+		return func() {}
+	}
+	s.progress.start = time.Now()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.progress.report(ctx, s.msgChan, stop)
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_SyncProgress(t *testing.T) {
+	slowTable := testTableSuccess()
+	slowTable.Name = "test_table_slow"
+	slowTable.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+		time.Sleep(200 * time.Millisecond)
+		return testResolverSuccess(ctx, meta, parent, res)
+	}
+
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))), WithStrategy(strategy))
+			msgs := make(chan message.SyncMessage, 500)
+			tables := schema.Tables{testTableSuccess(), slowTable}
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, WithSyncProgress(20*time.Millisecond)))
+			close(msgs)
+
+			var reports []*message.SyncProgress
+			for msg := range msgs {
+				if m, ok := msg.(*message.SyncProgress); ok {
+					reports = append(reports, m)
+				}
+			}
+			// the success table finishes right away, while the slow table is still in progress for a few reports
+			var found bool
+			for _, report := range reports {
+				require.Equal(t, uint64(2), report.PlannedWork)
+				require.LessOrEqual(t, report.Resources, uint64(2))
+				if report.CompletedWork == 1 && slices.Equal(report.TablesInProgress, []string{"test_table_slow"}) {
+					require.Equal(t, uint64(1), report.RemainingWork())
+					found = true
+				}
+			}
+			require.True(t, found, "expected a report with the slow table in progress")
+		})
+	}
+}
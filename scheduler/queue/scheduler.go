@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/cloudquery/plugin-sdk/v4/caser"
 	"github.com/cloudquery/plugin-sdk/v4/message"
@@ -23,6 +24,16 @@ type WorkUnit struct {
 	Table  *schema.Table
 	Client schema.ClientMeta
 	Parent *schema.Resource
+
+	// topLevel tracks the outstanding work of the top-level work unit this one descends from
+	topLevel *topLevelWork
+}
+
+// topLevelWork counts the pending work units of a top-level table client, including all of its relations.
+type topLevelWork struct {
+	table   *schema.Table
+	client  schema.ClientMeta
+	pending atomic.Int64
 }
 
 type Scheduler struct {
@@ -34,6 +45,12 @@ type Scheduler struct {
 	invocationID      string
 	seed              int64
 	errorClassifier   schema.ErrorClassifier
+	topLevelStart     func(table *schema.Table, client schema.ClientMeta)
+	topLevelDone      func(table *schema.Table, client schema.ClientMeta)
+	rateLimit         func(ctx context.Context, clientID string, tableName string) error
+	skip              func(table *schema.Table, client schema.ClientMeta, parent *schema.Resource) bool
+	resolved          func(resource *schema.Resource, client schema.ClientMeta)
+	retryPolicy       *schema.RetryPolicy
 }
 
 type Option func(*Scheduler)
@@ -68,6 +85,49 @@ func WithErrorClassifier(classifier schema.ErrorClassifier) Option {
 	}
 }
 
+// WithTopLevelStartFunc sets a function that is called when a top-level table client starts being resolved.
+func WithTopLevelStartFunc(fn func(table *schema.Table, client schema.ClientMeta)) Option {
+	return func(d *Scheduler) {
+		d.topLevelStart = fn
+	}
+}
+
+// WithTopLevelDoneFunc sets a function that is called once a top-level table client and all of its relations are resolved.
+func WithTopLevelDoneFunc(fn func(table *schema.Table, client schema.ClientMeta)) Option {
+	return func(d *Scheduler) {
+		d.topLevelDone = fn
+	}
+}
+
+// WithRateLimitFunc sets a function that is called before every resolver call, blocking until the call is allowed.
+func WithRateLimitFunc(fn func(ctx context.Context, clientID string, tableName string) error) Option {
+	return func(d *Scheduler) {
+		d.rateLimit = fn
+	}
+}
+
+// WithSkipFunc sets a function that is called before a work unit is resolved. If it returns true, the work unit
+// (and therefore all of its relations) is skipped.
+func WithSkipFunc(fn func(table *schema.Table, client schema.ClientMeta, parent *schema.Resource) bool) Option {
+	return func(d *Scheduler) {
+		d.skip = fn
+	}
+}
+
+// WithResolvedFunc sets a function that is called for every resolved resource, before it is sent.
+func WithResolvedFunc(fn func(resource *schema.Resource, client schema.ClientMeta)) Option {
+	return func(d *Scheduler) {
+		d.resolved = fn
+	}
+}
+
+// WithRetryPolicy sets the retry policy of the tables that don't have one.
+func WithRetryPolicy(policy *schema.RetryPolicy) Option {
+	return func(d *Scheduler) {
+		d.retryPolicy = policy
+	}
+}
+
 func NewShuffleQueueScheduler(logger zerolog.Logger, m *metrics.Metrics, seed int64, opts ...Option) *Scheduler {
 	scheduler := &Scheduler{
 		logger:       logger,
@@ -91,6 +151,8 @@ func (d *Scheduler) Sync(ctx context.Context, tableClients []WorkUnit, resolvedR
 	}
 	queue := NewConcurrentRandomQueue[WorkUnit](d.seed, len(tableClients))
 	for _, tc := range tableClients {
+		tc.topLevel = &topLevelWork{table: tc.Table, client: tc.Client}
+		tc.topLevel.pending.Store(1)
 		queue.Push(tc)
 	}
 
@@ -112,6 +174,12 @@ func (d *Scheduler) Sync(ctx context.Context, tableClients []WorkUnit, resolvedR
 				d.metrics,
 				msgChan,
 				d.errorClassifier,
+				d.topLevelStart,
+				d.topLevelDone,
+				d.rateLimit,
+				d.skip,
+				d.resolved,
+				d.retryPolicy,
 			).work(ctx, activeWorkSignal)
 			return nil
 		})
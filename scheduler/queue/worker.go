@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sync"
@@ -34,6 +35,12 @@ type worker struct {
 	// message channel for sending SyncError messages
 	msgChan         chan<- message.SyncMessage
 	errorClassifier schema.ErrorClassifier
+	topLevelStart   func(table *schema.Table, client schema.ClientMeta)
+	topLevelDone    func(table *schema.Table, client schema.ClientMeta)
+	rateLimit       func(ctx context.Context, clientID string, tableName string) error
+	skip            func(table *schema.Table, client schema.ClientMeta, parent *schema.Resource) bool
+	resolved        func(resource *schema.Resource, client schema.ClientMeta)
+	retryPolicy     *schema.RetryPolicy
 }
 
 func (w *worker) work(ctx context.Context, activeWorkSignal *activeWorkSignal) {
@@ -41,7 +48,10 @@ func (w *worker) work(ctx context.Context, activeWorkSignal *activeWorkSignal) {
 		// the work unit was already marked active by the dispatcher before it was
 		// handed off, so the dispatcher can never observe an idle state while a
 		// job is in flight between the queue and a worker
-		w.resolveTable(ctx, j.Table, j.Client, j.Parent)
+		if w.skip == nil || !w.skip(j.Table, j.Client, j.Parent) {
+			w.resolveTable(ctx, j.Table, j.Client, j.Parent, j.topLevel)
+		}
+		w.done(j.topLevel)
 
 		activeWorkSignal.Done()
 	}
@@ -59,6 +69,12 @@ func newWorker(
 	m *metrics.Metrics,
 	msgChan chan<- message.SyncMessage,
 	errorClassifier schema.ErrorClassifier,
+	topLevelStart func(table *schema.Table, client schema.ClientMeta),
+	topLevelDone func(table *schema.Table, client schema.ClientMeta),
+	rateLimit func(ctx context.Context, clientID string, tableName string) error,
+	skip func(table *schema.Table, client schema.ClientMeta, parent *schema.Resource) bool,
+	resolved func(resource *schema.Resource, client schema.ClientMeta),
+	retryPolicy *schema.RetryPolicy,
 ) *worker {
 	return &worker{
 		jobs:              jobs,
@@ -71,10 +87,33 @@ func newWorker(
 		metrics:           m,
 		msgChan:           msgChan,
 		errorClassifier:   errorClassifier,
+		topLevelStart:     topLevelStart,
+		topLevelDone:      topLevelDone,
+		rateLimit:         rateLimit,
+		skip:              skip,
+		resolved:          resolved,
+		retryPolicy:       retryPolicy,
+	}
+}
+
+func (w *worker) waitRateLimit(ctx context.Context, clientID string, tableName string) error {
+	if w.rateLimit == nil {
+		return nil
+	}
+	return w.rateLimit(ctx, clientID, tableName)
+}
+
+// done marks a work unit descending from topLevel as resolved, calling topLevelDone once no work is left.
+func (w *worker) done(topLevel *topLevelWork) {
+	if topLevel == nil || topLevel.pending.Add(-1) > 0 {
+		return
+	}
+	if w.topLevelDone != nil {
+		w.topLevelDone(topLevel.table, topLevel.client)
 	}
 }
 
-func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource) {
+func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, topLevel *topLevelWork) {
 	clientName := client.ID()
 	ctx, span := otel.Tracer(metrics.ResourceName).Start(ctx,
 		"sync.table."+table.Name,
@@ -89,6 +128,9 @@ func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client s
 	startTime := time.Now()
 	if parent == nil { // Log only for root tables, otherwise we spam too much.
 		logger.Info().Msg("top level table resolver started")
+		if w.topLevelStart != nil {
+			w.topLevelStart(table, client)
+		}
 	}
 
 	selector := w.metrics.NewSelector(clientName, table.Name)
@@ -115,18 +157,23 @@ func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client s
 			}
 			close(res)
 		}()
-		if err := table.Resolver(ctx, client, parent, res); err != nil {
+		if err := w.waitRateLimit(ctx, clientName, table.Name); err != nil {
+			logger.Debug().Err(err).Msg("table resolver cancelled while waiting for rate limit")
+			return
+		}
+		retries, err := resolvers.RetryTableResolver(ctx, logger, w.metrics, resolvers.RetryPolicy(table, w.retryPolicy), table, client, parent, res)
+		if err != nil {
 			event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhaseTableResolver}
 			if w.errorClassifier.Suppress(ctx, err, event) {
 				logger.Debug().Err(err).Msg("table resolver finished with suppressed error")
 				return
 			}
-			logger.Error().Err(err).Msg("table resolver finished with error")
-			w.metrics.AddErrors(ctx, 1, selector)
+			logger.Error().Err(err).Int("retries", retries).Msg("table resolver finished with error")
+			w.metrics.AddError(ctx, err, selector)
 			// Send SyncError message
 			syncErrorMsg := &message.SyncError{
 				TableName: table.Name,
-				Error:     err.Error(),
+				Error:     resolvers.RetriedErrorMessage(err, retries),
 			}
 			w.msgChan <- syncErrorMsg
 			return
@@ -134,7 +181,7 @@ func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client s
 	}()
 
 	for r := range res {
-		w.resolveResource(ctx, table, client, parent, r)
+		w.resolveResource(ctx, table, client, parent, r, topLevel)
 	}
 
 	endTime := time.Now()
@@ -144,7 +191,7 @@ func (w *worker) resolveTable(ctx context.Context, table *schema.Table, client s
 	}
 }
 
-func (w *worker) resolveResource(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, resources any) {
+func (w *worker) resolveResource(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, resources any, topLevel *topLevelWork) {
 	resourcesSlice := helpers.InterfaceSlice(resources)
 	if len(resourcesSlice) == 0 {
 		return
@@ -163,24 +210,33 @@ func (w *worker) resolveResource(ctx context.Context, table *schema.Table, clien
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				resolvedResources := resolvers.ResolveResourcesChunkWithClassifier(ctx, w.logger, w.metrics, table, client, parent, chunks[i], w.caser, w.errorClassifier)
+				if err := w.waitRateLimit(ctx, client.ID(), table.Name); err != nil {
+					w.logger.Debug().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver cancelled while waiting for rate limit")
+					return
+				}
+				resolvedResources := resolvers.ResolveResourcesChunkWithRetryPolicy(ctx, w.logger, w.metrics, table, client, parent, chunks[i], w.caser, w.errorClassifier, w.retryPolicy)
 				for _, resolvedResource := range resolvedResources {
 					if err := resolvedResource.CalculateCQID(w.deterministicCQID); err != nil {
 						w.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with primary key calculation error")
-						w.metrics.AddErrors(ctx, 1, selector)
+						w.metrics.AddError(ctx, err, selector)
 						return
 					}
 					if err := resolvedResource.StoreCQClientID(client.ID()); err != nil {
 						w.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("failed to store _cq_client_id")
 					}
 					if err := resolvedResource.Validate(); err != nil {
-						switch err.(type) {
-						case *schema.PKError:
+						var pkErr *schema.PKError
+						if errors.As(err, &pkErr) {
 							w.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation error")
-							w.metrics.AddErrors(ctx, 1, selector)
+							w.metrics.AddError(ctx, err, selector)
 							return
-						case *schema.PKComponentError:
-							w.logger.Warn().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation warning")
+						}
+						var pkComponentErr *schema.PKComponentError
+						if errors.As(err, &pkComponentErr) {
+							w.logger.Warn().Err(pkComponentErr).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation warning")
+						}
+						if resolvers.HandleColumnValidationErrors(ctx, w.logger, w.metrics, selector, client, resolvedResource, err, w.errorClassifier) {
+							continue
 						}
 					}
 					select {
@@ -195,13 +251,20 @@ func (w *worker) resolveResource(ctx context.Context, table *schema.Table, clien
 
 	for resource := range resourcesChan {
 		resource := resource
+		if w.resolved != nil {
+			w.resolved(resource, client)
+		}
 		w.resolvedResources <- resource
 		for _, r := range resource.Table.Relations {
 			relation := r
+			if topLevel != nil {
+				topLevel.pending.Add(1)
+			}
 			w.queue.Push(WorkUnit{
-				Table:  relation,
-				Client: client,
-				Parent: resource,
+				Table:    relation,
+				Client:   client,
+				Parent:   resource,
+				topLevel: topLevel,
 			})
 		}
 	}
@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultThrottleMinBackoff = 100 * time.Millisecond
+	DefaultThrottleMaxBackoff = 30 * time.Second
+)
+
+// RateLimiter limits the rate of resolver calls made by the scheduler.
+// Wait is called before every table resolver and resource resolver call, after the concurrency semaphores
+// are acquired, so that waiting calls hold a slot in the worker pool.
+// Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	// Wait blocks until a call for the given client and table is allowed, or returns an error if ctx is done.
+	Wait(ctx context.Context, clientID string, tableName string) error
+}
+
+// WithRateLimiter sets the rate limiter consulted before every resolver call.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(s *Scheduler) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithThrottleBackoff sets the bounds of the adaptive backoff applied to (client, table) pairs whose resolver errors
+// are reported as throttling errors by the ErrorClassifier, see schema.ErrorEvent.ReportThrottled.
+// The delay starts at minDelay, doubles on every throttling error up to maxDelay and halves once a full
+// delay passes without throttling. Defaults to DefaultThrottleMinBackoff and DefaultThrottleMaxBackoff.
+func WithThrottleBackoff(minDelay, maxDelay time.Duration) Option {
+	return func(s *Scheduler) {
+		s.throttleMinBackoff = minDelay
+		s.throttleMaxBackoff = maxDelay
+	}
+}
+
+type rateLimitKey struct {
+	clientID  string
+	tableName string
+}
+
+// NewClientRateLimiter returns a RateLimiter with a token bucket per client ID, shared by all tables of the client.
+// Each bucket allows ratePerSecond calls on average and bursts of up to burst calls.
+func NewClientRateLimiter(ratePerSecond float64, burst int) RateLimiter {
+	return &tokenBucketLimiter{
+		rate:    ratePerSecond,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type tokenBucketLimiter struct {
+	rate    float64
+	burst   float64
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func (l *tokenBucketLimiter) Wait(ctx context.Context, clientID string, _ string) error {
+	for {
+		delay := l.reserve(clientID)
+		if delay == 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token from the client's bucket, returning 0, or returns the time until a token is available.
+func (l *tokenBucketLimiter) reserve(clientID string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[clientID]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[clientID] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return max(time.Duration((1-b.tokens)/l.rate*float64(time.Second)), time.Millisecond)
+}
+
+type backoffState struct {
+	delay     time.Duration
+	throttled time.Time
+}
+
+// throttleBackoff keeps an adaptive delay for every throttled (client, table) pair.
+type throttleBackoff struct {
+	minDelay time.Duration
+	maxDelay time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	state    map[rateLimitKey]*backoffState
+}
+
+func newThrottleBackoff(minDelay, maxDelay time.Duration) *throttleBackoff {
+	return &throttleBackoff{
+		minDelay: minDelay,
+		maxDelay: max(minDelay, maxDelay),
+		now:      time.Now,
+		state:    make(map[rateLimitKey]*backoffState),
+	}
+}
+
+// throttled doubles the delay of the pair, starting at minDelay.
+func (b *throttleBackoff) throttled(key rateLimitKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.state[key]
+	if !ok {
+		st = &backoffState{}
+		b.state[key] = st
+	}
+	st.delay = min(max(st.delay*2, b.minDelay), b.maxDelay)
+	st.throttled = b.now()
+}
+
+// delay returns the current delay of the pair, halving it if a full delay passed since the last throttling error.
+func (b *throttleBackoff) delay(key rateLimitKey) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.state[key]
+	if !ok {
+		return 0
+	}
+	if now := b.now(); now.Sub(st.throttled) > st.delay {
+		st.delay /= 2
+		st.throttled = now
+		if st.delay < b.minDelay {
+			delete(b.state, key)
+			return 0
+		}
+	}
+	return st.delay
+}
+
+// classifier wraps the error classifier so that the throttling errors it reports slow down the table client.
+func (b *throttleBackoff) classifier(next ErrorClassifier) ErrorClassifier {
+	return func(ctx context.Context, err error, event ErrorEvent) bool {
+		if event.Table != nil && event.Client != nil {
+			key := rateLimitKey{clientID: event.Client.ID(), tableName: event.Table.Name}
+			event.OnThrottled = func() {
+				b.throttled(key)
+			}
+		}
+		return next(ctx, err, event)
+	}
+}
+
+// waitRateLimit blocks until a resolver call for the client and table is allowed by the rate limiter
+// and the throttling backoff, or returns an error if ctx is done.
+func (s *Scheduler) waitRateLimit(ctx context.Context, clientID string, tableName string) error {
+	if s.rateLimiter != nil {
+		if err := s.rateLimiter.Wait(ctx, clientID, tableName); err != nil {
+			return err
+		}
+	}
+	if s.throttleBackoff != nil {
+		if delay := s.throttleBackoff.delay(rateLimitKey{clientID: clientID, tableName: tableName}); delay > 0 {
+			return sleep(ctx, delay)
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
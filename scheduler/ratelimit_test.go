@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type testRateLimiter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (l *testRateLimiter) Wait(_ context.Context, clientID string, tableName string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls[clientID+":"+tableName]++
+	return nil
+}
+
+func TestScheduler_RateLimiter(t *testing.T) {
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			limiter := &testRateLimiter{calls: make(map[string]int)}
+			sc := NewScheduler(
+				WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+				WithStrategy(strategy),
+				WithRateLimiter(limiter),
+			)
+			msgs := make(chan message.SyncMessage, 500)
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{testTableRelationSuccess()}, msgs))
+			close(msgs)
+
+			// one table resolver call and one resource resolver call per table
+			require.Equal(t, map[string]int{
+				"test:test_table_relation_success": 2,
+				"test:test_table_success":          2,
+			}, limiter.calls)
+		})
+	}
+}
+
+// testClock is a fake clock advanced by the tests.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	limiter := NewClientRateLimiter(20, 2).(*tokenBucketLimiter)
+	limiter.now = clock.Now
+
+	// the burst is allowed immediately
+	require.Zero(t, limiter.reserve("a"))
+	require.Zero(t, limiter.reserve("a"))
+
+	// other clients have their own bucket
+	require.Zero(t, limiter.reserve("b"))
+
+	// the next call waits for a token to be refilled
+	require.Equal(t, 50*time.Millisecond, limiter.reserve("a"))
+	clock.advance(25 * time.Millisecond)
+	require.Equal(t, 25*time.Millisecond, limiter.reserve("a"))
+	clock.advance(25 * time.Millisecond)
+	require.Zero(t, limiter.reserve("a"))
+
+	// the bucket doesn't fill up past the burst
+	clock.advance(time.Hour)
+	require.Zero(t, limiter.reserve("a"))
+	require.Zero(t, limiter.reserve("a"))
+	require.Positive(t, limiter.reserve("a"))
+
+	ctx := context.Background()
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.NoError(t, limiter.Wait(ctx, "c", "t1"))
+	require.NoError(t, limiter.Wait(ctx, "c", "t1"))
+	require.ErrorIs(t, limiter.Wait(cancelled, "c", "t1"), context.Canceled)
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	b := newThrottleBackoff(10*time.Millisecond, 40*time.Millisecond)
+	b.now = clock.Now
+	key := rateLimitKey{clientID: "a", tableName: "t"}
+	require.Zero(t, b.delay(key))
+
+	b.throttled(key)
+	require.Equal(t, 10*time.Millisecond, b.delay(key))
+	b.throttled(key)
+	require.Equal(t, 20*time.Millisecond, b.delay(key))
+	b.throttled(key)
+	b.throttled(key)
+	require.Equal(t, 40*time.Millisecond, b.delay(key))
+
+	// other pairs are not affected
+	require.Zero(t, b.delay(rateLimitKey{clientID: "a", tableName: "other"}))
+
+	// the delay halves once a full delay passes without throttling
+	clock.advance(40 * time.Millisecond)
+	require.Equal(t, 40*time.Millisecond, b.delay(key))
+	clock.advance(time.Millisecond)
+	require.Equal(t, 20*time.Millisecond, b.delay(key))
+	clock.advance(21 * time.Millisecond)
+	require.Equal(t, 10*time.Millisecond, b.delay(key))
+	clock.advance(11 * time.Millisecond)
+	require.Zero(t, b.delay(key))
+}
+
+func TestScheduler_ThrottleBackoff(t *testing.T) {
+	errThrottled := errors.New("throttled")
+	table := &schema.Table{
+		Name: "test_table_throttled",
+		Resolver: func(context.Context, schema.ClientMeta, *schema.Resource, chan<- any) error {
+			return errThrottled
+		},
+	}
+	sc := NewScheduler(
+		WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+		WithThrottleBackoff(time.Second, time.Minute),
+		WithErrorClassifier(func(_ context.Context, err error, event ErrorEvent) bool {
+			if errors.Is(err, errThrottled) {
+				event.ReportThrottled()
+			}
+			return false
+		}),
+	)
+	msgs := make(chan message.SyncMessage, 500)
+	require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{table}, msgs))
+	close(msgs)
+
+	require.Equal(t, time.Second, sc.throttleBackoff.delay(rateLimitKey{clientID: "test", tableName: table.Name}))
+}
+
+func TestErrorEvent_ReportThrottled(t *testing.T) {
+	// events are only reported by the scheduler
+	require.NotPanics(t, ErrorEvent{}.ReportThrottled)
+
+	var throttled int
+	ErrorEvent{OnThrottled: func() { throttled++ }}.ReportThrottled()
+	require.Equal(t, 1, throttled)
+}
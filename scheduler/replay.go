@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/cloudquery/plugin-sdk/v4/helpers"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// WithRecording records every item sent by the table resolvers to the file at path, as JSON lines keyed by table,
+// client ID and parent item, so that the sync can be replayed offline with WithReplay.
+// Table resolvers whose items fail to be recorded return an error once they're done, as the recording is incomplete.
+func WithRecording(path string) SyncOption {
+	return func(s *syncClient) {
+		s.recordPath = path
+	}
+}
+
+// WithReplay replaces the table resolvers with a replay of the items recorded by WithRecording in the file at path.
+// All the other resolvers run as usual, so they must not depend on network access for the replay to be offline.
+// Items are decoded into the types of the given sample values when the recorded type matches (e.g. pass
+// &types.Instance{} to decode *types.Instance items), otherwise they are decoded as generic JSON values (map[string]any).
+func WithReplay(path string, itemTypes ...any) SyncOption {
+	return func(s *syncClient) {
+		s.replayPath = path
+		s.replayTypes = itemTypes
+	}
+}
+
+// recordedItem is a single line of a recording.
+type recordedItem struct {
+	Table  string `json:"table"`
+	Client string `json:"client"`
+	// Parent is the hash of the parent resource item, empty for top-level tables
+	Parent string          `json:"parent,omitempty"`
+	Type   string          `json:"type"`
+	Item   json.RawMessage `json:"item"`
+}
+
+type recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newRecorder(path string) (*recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	return &recorder{f: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (r *recorder) record(table *schema.Table, client schema.ClientMeta, parent *schema.Resource, item any) error {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item of table %s: %w", table.Name, err)
+	}
+	parentHash, err := parentItemHash(parent)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(recordedItem{
+		Table:  table.Name,
+		Client: client.ID(),
+		Parent: parentHash,
+		Type:   typeName(reflect.TypeOf(item)),
+		Item:   b,
+	})
+}
+
+func (r *recorder) close() error {
+	if err := r.w.Flush(); err != nil {
+		_ = r.f.Close()
+		return fmt.Errorf("failed to write recording file: %w", err)
+	}
+	return r.f.Close()
+}
+
+type replayer struct {
+	types map[string]reflect.Type
+	// items holds the recorded items by table, client and parent
+	items map[string][]recordedItem
+}
+
+func newReplayer(path string, itemTypes []any) (*replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer f.Close()
+
+	r := &replayer{types: make(map[string]reflect.Type), items: make(map[string][]recordedItem)}
+	for _, sample := range itemTypes {
+		t := reflect.TypeOf(sample)
+		r.types[typeName(t)] = t
+	}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var item recordedItem
+		if err := dec.Decode(&item); err != nil {
+			return nil, fmt.Errorf("failed to decode replay file: %w", err)
+		}
+		key := replayKey(item.Table, item.Client, item.Parent)
+		r.items[key] = append(r.items[key], item)
+	}
+	return r, nil
+}
+
+func (r *replayer) replay(ctx context.Context, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+	parentHash, err := parentItemHash(parent)
+	if err != nil {
+		return err
+	}
+	for _, recorded := range r.items[replayKey(table.Name, client.ID(), parentHash)] {
+		item, err := r.decode(recorded)
+		if err != nil {
+			return fmt.Errorf("failed to decode recorded item of table %s: %w", table.Name, err)
+		}
+		select {
+		case res <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (r *replayer) decode(recorded recordedItem) (any, error) {
+	t, ok := r.types[recorded.Type]
+	if !ok {
+		var item any
+		err := json.Unmarshal(recorded.Item, &item)
+		return item, err
+	}
+	if t.Kind() == reflect.Pointer {
+		v := reflect.New(t.Elem())
+		err := json.Unmarshal(recorded.Item, v.Interface())
+		return v.Interface(), err
+	}
+	v := reflect.New(t)
+	err := json.Unmarshal(recorded.Item, v.Interface())
+	return v.Elem().Interface(), err
+}
+
+// wrapResolvers returns copies of the tables whose resolvers record or replay items.
+func (s *syncClient) wrapResolvers(tables schema.Tables) schema.Tables {
+	wrapped := make(schema.Tables, len(tables))
+	for i, table := range tables {
+		wrapped[i] = table.Copy(table.Parent)
+		s.wrapResolver(wrapped[i])
+	}
+	return wrapped
+}
+
+func (s *syncClient) wrapResolver(table *schema.Table) {
+	resolver := table.Resolver
+	switch {
+	case s.replayer != nil:
+		table.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+			return s.replayer.replay(ctx, table, meta, parent, res)
+		}
+	case s.recorder != nil && resolver != nil:
+		table.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+			recorded := make(chan any)
+			done := make(chan struct{})
+			var recordErr error
+			go func() {
+				defer close(done)
+				for r := range recorded {
+					for _, item := range helpers.InterfaceSlice(r) {
+						if err := s.recorder.record(table, meta, parent, item); err != nil && recordErr == nil {
+							recordErr = err
+						}
+					}
+					// items are still synced, only the recording is incomplete
+					res <- r
+				}
+			}()
+			err := resolver(ctx, meta, parent, recorded)
+			close(recorded)
+			<-done
+			return errors.Join(err, recordErr)
+		}
+	}
+	for _, relation := range table.Relations {
+		s.wrapResolver(relation)
+	}
+}
+
+func replayKey(table, client, parent string) string {
+	return table + "\x00" + client + "\x00" + parent
+}
+
+// parentItemHash returns a hash of the canonical JSON encoding of the parent item, so that it matches between the
+// recorded item and the item decoded on replay.
+func parentItemHash(parent *schema.Resource) (string, error) {
+	if parent == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(parent.GetItem())
+	if err != nil {
+		return "", fmt.Errorf("failed to encode parent item of table %s: %w", parent.Table.Name, err)
+	}
+	// decoding into a generic value and encoding it again sorts the object keys
+	var canonical any
+	if err := json.Unmarshal(b, &canonical); err != nil {
+		return "", err
+	}
+	if b, err = json.Marshal(canonical); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type testReplayItem struct {
+	Value int64
+	Name  string
+}
+
+func testReplayTables(resolver, childResolver schema.TableResolver) schema.Tables {
+	return schema.Tables{
+		{
+			Name:     "test_replay_parent",
+			Resolver: resolver,
+			Columns: []schema.Column{
+				{Name: "value", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "name", Type: arrow.BinaryTypes.String},
+			},
+			Relations: schema.Tables{
+				{
+					Name:     "test_replay_child",
+					Resolver: childResolver,
+					Columns: []schema.Column{
+						{Name: "value", Type: arrow.PrimitiveTypes.Int64},
+						{Name: "name", Type: arrow.BinaryTypes.String},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestScheduler_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	resolver := func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+		res <- []*testReplayItem{{Value: 1, Name: "a"}, {Value: 2, Name: "b"}}
+		return nil
+	}
+	childResolver := func(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+		item := parent.Item.(*testReplayItem)
+		res <- testReplayItem{Value: item.Value * 10, Name: item.Name + "_child"}
+		return nil
+	}
+	offline := func(context.Context, schema.ClientMeta, *schema.Resource, chan<- any) error {
+		return errors.New("no network access")
+	}
+
+	runSync := func(tables schema.Tables, opt SyncOption) map[string][]string {
+		sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+		msgs := make(chan message.SyncMessage, 500)
+		require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, opt))
+		close(msgs)
+		rows := make(map[string][]string)
+		for _, msg := range drain(msgs) {
+			switch m := msg.(type) {
+			case *message.SyncInsert:
+				name, _ := m.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+				for i := 0; i < int(m.Record.NumRows()); i++ {
+					rows[name] = append(rows[name], m.Record.Column(0).(*array.Int64).ValueStr(i)+":"+m.Record.Column(1).(*array.String).Value(i))
+				}
+			case *message.SyncError:
+				t.Fatalf("unexpected sync error for table %s: %s", m.TableName, m.Error)
+			}
+		}
+		return rows
+	}
+
+	recorded := runSync(testReplayTables(resolver, childResolver), WithRecording(path))
+	require.ElementsMatch(t, []string{"1:a", "2:b"}, recorded["test_replay_parent"])
+	require.ElementsMatch(t, []string{"10:a_child", "20:b_child"}, recorded["test_replay_child"])
+
+	// the child resolver type-asserts the parent item, so its type must be registered
+	replayed := runSync(testReplayTables(offline, offline), WithReplay(path, &testReplayItem{}))
+	require.ElementsMatch(t, recorded["test_replay_parent"], replayed["test_replay_parent"])
+	require.ElementsMatch(t, recorded["test_replay_child"], replayed["test_replay_child"])
+
+	// unregistered types are replayed as generic JSON values
+	replayed = runSync(testReplayTables(offline, offline), WithReplay(path))
+	require.ElementsMatch(t, recorded["test_replay_parent"], replayed["test_replay_parent"])
+	require.ElementsMatch(t, recorded["test_replay_child"], replayed["test_replay_child"])
+}
+
+func TestScheduler_RecordingError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	table := &schema.Table{
+		Name: "test_recording_error",
+		Resolver: func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+			// channels can't be encoded as JSON
+			res <- struct{ Ch chan int }{}
+			return nil
+		},
+		Columns: []schema.Column{
+			{
+				Name: "value",
+				Type: arrow.PrimitiveTypes.Int64,
+				Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+					return resource.Set(c.Name, 1)
+				},
+			},
+		},
+	}
+
+	sc := NewScheduler(WithLogger(zerolog.New(zerolog.NewTestWriter(t))))
+	msgs := make(chan message.SyncMessage, 500)
+	require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{table}, msgs, WithRecording(path)))
+	close(msgs)
+
+	// the item is still synced, but the table resolver fails as the recording is incomplete
+	all := message.SyncMessages(drain(msgs))
+	require.EqualValues(t, 1, all.InsertItems())
+	var errs []*message.SyncError
+	for _, msg := range all {
+		if m, ok := msg.(*message.SyncError); ok {
+			errs = append(errs, m)
+		}
+	}
+	require.Len(t, errs, 1)
+	require.Equal(t, table.Name, errs[0].TableName)
+	require.Contains(t, errs[0].Error, "failed to encode item of table test_recording_error")
+}
@@ -86,6 +86,44 @@ func TestResolveResourcesChunk_ErrorClassifier(t *testing.T) {
 			},
 			wantPhase: schema.ErrorPhasePostResourceResolver,
 		},
+		{
+			name: "column chunk resolver",
+			table: func() *schema.Table {
+				return &schema.Table{
+					Name: "test_table",
+					Columns: []schema.Column{
+						{
+							Name: "test_column",
+							Type: arrow.PrimitiveTypes.Int64,
+							ChunkResolver: &schema.ColumnChunkResolver{
+								ChunkSize: 10,
+								RowsResolver: func(_ context.Context, _ schema.ClientMeta, _ []*schema.Resource, _ schema.Column) error {
+									return errResolverBoom
+								},
+							},
+						},
+					},
+				}
+			},
+			wantPhase:  schema.ErrorPhaseColumnResolver,
+			wantColumn: "test_column",
+		},
+		{
+			name: "post resource chunk resolver",
+			table: func() *schema.Table {
+				return &schema.Table{
+					Name: "test_table",
+					PostResourceChunkResolver: &schema.RowsChunkResolver{
+						ChunkSize: 10,
+						RowsResolver: func(_ context.Context, _ schema.ClientMeta, _ []*schema.Resource) error {
+							return errResolverBoom
+						},
+					},
+					Columns: []schema.Column{{Name: "test_column", Type: arrow.PrimitiveTypes.Int64}},
+				}
+			},
+			wantPhase: schema.ErrorPhasePostResourceResolver,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			client := testClient{}
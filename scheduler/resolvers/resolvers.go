@@ -11,10 +11,11 @@ import (
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/getsentry/sentry-go"
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
 	"github.com/thoas/go-funk"
 )
 
-func resolveColumn(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, selector metrics.Selector, client schema.ClientMeta, resource *schema.Resource, column schema.Column, c *caser.Caser, classifier schema.ErrorClassifier) {
+func resolveColumn(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, selector metrics.Selector, client schema.ClientMeta, resource *schema.Resource, column schema.Column, c *caser.Caser, classifier schema.ErrorClassifier, policy *schema.RetryPolicy) {
 	columnStartTime := time.Now()
 	defer func() {
 		if err := recover(); err != nil {
@@ -36,11 +37,14 @@ func resolveColumn(ctx context.Context, logger zerolog.Logger, m *metrics.Metric
 			return
 		}
 		logger.Error().Err(err).Msg("column resolver finished with error")
-		m.AddErrors(ctx, 1, selector)
+		m.AddError(ctx, err, selector)
 	}
 
 	if column.Resolver != nil {
-		if err := column.Resolver(ctx, client, resource, column); err != nil {
+		err := retry(ctx, logger, m, selector, policy, func() error {
+			return column.Resolver(ctx, client, resource, column)
+		})
+		if err != nil {
 			handleErr(err)
 		}
 	} else {
@@ -54,13 +58,113 @@ func resolveColumn(ctx context.Context, logger zerolog.Logger, m *metrics.Metric
 	}
 }
 
+// RetryPolicy returns the retry policy of the table, or defaultPolicy if it has none.
+func RetryPolicy(table *schema.Table, defaultPolicy *schema.RetryPolicy) *schema.RetryPolicy {
+	if table.RetryPolicy != nil {
+		return table.RetryPolicy
+	}
+	return defaultPolicy
+}
+
+// RetryTableResolver calls the table resolver with the retry policy, counting the retries in the metrics.
+// A failed call is only retried if it didn't send any item, so that items aren't sent twice.
+// It returns the number of retries along with the error of the last call.
+func RetryTableResolver(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, policy *schema.RetryPolicy, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, res chan<- any) (int, error) {
+	if policy == nil || policy.MaxAttempts < 2 {
+		return 0, table.Resolver(ctx, client, parent, res)
+	}
+	selector := m.NewSelector(client.ID(), table.Name)
+	sent := false
+	attemptPolicy := *policy
+	retryable := policy.Retryable
+	attemptPolicy.Retryable = func(err error) bool {
+		return !sent && (retryable == nil || retryable(err))
+	}
+	return attemptPolicy.Do(ctx, func() error {
+		attemptRes := make(chan any)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for item := range attemptRes {
+				sent = true
+				res <- item
+			}
+		}()
+		defer func() {
+			close(attemptRes)
+			<-done
+		}()
+		return table.Resolver(ctx, client, parent, attemptRes)
+	}, func(retry int, err error) {
+		logger.Debug().Err(err).Int("retry", retry).Msg("retrying table resolver")
+		m.AddRetries(ctx, 1, selector)
+	})
+}
+
+// RetriedErrorMessage returns the message of the error, noting the number of attempts if the call was retried.
+func RetriedErrorMessage(err error, retries int) string {
+	if retries == 0 {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s (failed after %d attempts)", err, retries+1)
+}
+
+// retry calls fn with the retry policy, counting the retries in the metrics.
+func retry(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, selector metrics.Selector, policy *schema.RetryPolicy, fn func() error) error {
+	_, err := policy.Do(ctx, fn, func(retry int, err error) {
+		logger.Debug().Err(err).Int("retry", retry).Msg("retrying resolver")
+		m.AddRetries(ctx, 1, selector)
+	})
+	return err
+}
+
+// HandleColumnValidationErrors reports the column validation errors returned by schema.Resource.Validate to the
+// classifier, logging and counting the ones that are not suppressed. It returns true if the resource must be dropped,
+// i.e. if any of the errors wasn't suppressed.
+func HandleColumnValidationErrors(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, selector metrics.Selector, client schema.ClientMeta, resource *schema.Resource, err error, classifier schema.ErrorClassifier) bool {
+	drop := false
+	for _, validationErr := range columnValidationErrors(err) {
+		event := schema.ErrorEvent{Table: resource.Table, Client: client, Phase: schema.ErrorPhaseColumnValidation, Column: resource.Table.Columns.Get(validationErr.Column)}
+		if classifier.Suppress(ctx, validationErr, event) {
+			logger.Debug().Str("table", resource.Table.Name).Str("column", validationErr.Column).Err(validationErr).Msg("column validation finished with suppressed error")
+			continue
+		}
+		logger.Error().Str("table", resource.Table.Name).Str("column", validationErr.Column).Err(validationErr).Msg("column validation finished with error")
+		m.AddError(ctx, validationErr, selector)
+		drop = true
+	}
+	return drop
+}
+
+func columnValidationErrors(err error) []*schema.ColumnValidationError {
+	switch e := err.(type) {
+	case *schema.ColumnValidationError:
+		return []*schema.ColumnValidationError{e}
+	case interface{ Unwrap() []error }:
+		var errs []*schema.ColumnValidationError
+		for _, err := range e.Unwrap() {
+			errs = append(errs, columnValidationErrors(err)...)
+		}
+		return errs
+	default:
+		return nil
+	}
+}
+
 // Deprecated: use ResolveResourcesChunkWithClassifier. This retains the original
 // signature and resolves with a nil classifier, so every error is raised.
 func ResolveResourcesChunk(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, chunk []any, c *caser.Caser) []*schema.Resource {
 	return ResolveResourcesChunkWithClassifier(ctx, logger, m, table, client, parent, chunk, c, nil)
 }
 
+// ResolveResourcesChunkWithClassifier resolves the chunk with the retry policy of the table, if any.
 func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, chunk []any, c *caser.Caser, classifier schema.ErrorClassifier) []*schema.Resource {
+	return ResolveResourcesChunkWithRetryPolicy(ctx, logger, m, table, client, parent, chunk, c, classifier, nil)
+}
+
+// ResolveResourcesChunkWithRetryPolicy resolves the chunk, retrying the failed resolver calls with the retry policy
+// of the table, or defaultPolicy if the table has none.
+func ResolveResourcesChunkWithRetryPolicy(ctx context.Context, logger zerolog.Logger, m *metrics.Metrics, table *schema.Table, client schema.ClientMeta, parent *schema.Resource, chunk []any, c *caser.Caser, classifier schema.ErrorClassifier, defaultPolicy *schema.RetryPolicy) []*schema.Resource {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
 
@@ -74,6 +178,7 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 	tableLogger := logger.With().Str("table", table.Name).Str("client", clientID).Logger()
 
 	selector := m.NewSelector(clientID, table.Name)
+	policy := RetryPolicy(table, defaultPolicy)
 
 	defer func() {
 		if err := recover(); err != nil {
@@ -84,13 +189,16 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 	}()
 
 	if table.PreResourceChunkResolver != nil {
-		if err := table.PreResourceChunkResolver.RowsResolver(ctx, client, resources); err != nil {
+		err := retry(ctx, tableLogger, m, selector, policy, func() error {
+			return table.PreResourceChunkResolver.RowsResolver(ctx, client, resources)
+		})
+		if err != nil {
 			event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhasePreResourceChunkResolver}
 			if classifier.Suppress(ctx, err, event) {
 				tableLogger.Debug().Err(err).Msg("pre resource chunk resolver finished with suppressed error")
 			} else {
 				tableLogger.Error().Stack().Err(err).Msg("pre resource chunk resolver finished with error")
-				m.AddErrors(ctx, 1, selector)
+				m.AddError(ctx, err, selector)
 			}
 			return nil
 		}
@@ -99,7 +207,10 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 	if table.PreResourceResolver != nil {
 		filtered := resources[:0]
 		for _, resource := range resources {
-			if err := table.PreResourceResolver(ctx, client, resource); err != nil {
+			err := retry(ctx, tableLogger, m, selector, policy, func() error {
+				return table.PreResourceResolver(ctx, client, resource)
+			})
+			if err != nil {
 				event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhasePreResourceResolver}
 				suppress := classifier.Suppress(ctx, err, event)
 				switch {
@@ -111,11 +222,11 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 					continue
 				case ctx.Err() != nil:
 					tableLogger.Error().Err(err).Msg("pre resource resolver failed, context cancelled")
-					m.AddErrors(ctx, 1, selector)
+					m.AddError(ctx, err, selector)
 					return nil
 				default:
 					tableLogger.Error().Err(err).Msg("pre resource resolver failed")
-					m.AddErrors(ctx, 1, selector)
+					m.AddError(ctx, err, selector)
 					continue
 				}
 			}
@@ -125,19 +236,61 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 	}
 	for _, resource := range resources {
 		for _, column := range table.Columns {
-			resolveColumn(ctx, tableLogger, m, selector, client, resource, column, c, classifier)
+			if column.ChunkResolver != nil {
+				continue
+			}
+			resolveColumn(ctx, tableLogger, m, selector, client, resource, column, c, classifier, policy)
+		}
+	}
+	for _, column := range table.Columns {
+		if column.ChunkResolver == nil {
+			continue
+		}
+		for _, resourcesChunk := range chunkResources(resources, column.ChunkResolver.ChunkSize) {
+			err := retry(ctx, tableLogger, m, selector, policy, func() error {
+				return column.ChunkResolver.RowsResolver(ctx, client, resourcesChunk, column)
+			})
+			if err != nil {
+				event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhaseColumnResolver, Column: &column}
+				if classifier.Suppress(ctx, err, event) {
+					tableLogger.Debug().Str("column", column.Name).Err(err).Msg("column chunk resolver finished with suppressed error")
+				} else {
+					tableLogger.Error().Str("column", column.Name).Err(err).Msg("column chunk resolver finished with error")
+					m.AddError(ctx, err, selector)
+				}
+			}
 		}
 	}
 
 	if table.PostResourceResolver != nil {
 		for _, resource := range resources {
-			if err := table.PostResourceResolver(ctx, client, resource); err != nil {
+			err := retry(ctx, tableLogger, m, selector, policy, func() error {
+				return table.PostResourceResolver(ctx, client, resource)
+			})
+			if err != nil {
 				event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhasePostResourceResolver}
 				if classifier.Suppress(ctx, err, event) {
 					tableLogger.Debug().Err(err).Msg("post resource resolver finished with suppressed error")
 				} else {
 					tableLogger.Error().Stack().Err(err).Msg("post resource resolver finished with error")
-					m.AddErrors(ctx, 1, selector)
+					m.AddError(ctx, err, selector)
+				}
+			}
+		}
+	}
+
+	if table.PostResourceChunkResolver != nil {
+		for _, resourcesChunk := range chunkResources(resources, table.PostResourceChunkResolver.ChunkSize) {
+			err := retry(ctx, tableLogger, m, selector, policy, func() error {
+				return table.PostResourceChunkResolver.RowsResolver(ctx, client, resourcesChunk)
+			})
+			if err != nil {
+				event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhasePostResourceResolver}
+				if classifier.Suppress(ctx, err, event) {
+					tableLogger.Debug().Err(err).Msg("post resource chunk resolver finished with suppressed error")
+				} else {
+					tableLogger.Error().Stack().Err(err).Msg("post resource chunk resolver finished with error")
+					m.AddError(ctx, err, selector)
 				}
 			}
 		}
@@ -146,3 +299,14 @@ func ResolveResourcesChunkWithClassifier(ctx context.Context, logger zerolog.Log
 	m.AddResources(ctx, int64(len(resources)), selector)
 	return resources
 }
+
+// chunkResources splits resources into chunks of up to size resources, or a single chunk if size isn't positive.
+func chunkResources(resources []*schema.Resource, size int) [][]*schema.Resource {
+	if len(resources) == 0 {
+		return nil
+	}
+	if size <= 0 {
+		return [][]*schema.Resource{resources}
+	}
+	return lo.Chunk(resources, size)
+}
@@ -140,3 +140,120 @@ func TestResolveResourcesChunk_PreResourceResolverContextCancelled(t *testing.T)
 	require.Equal(t, uint64(1), m.GetErrors(selector), "cancellation should be counted as a single error, not one per remaining resource")
 	require.Equal(t, uint64(0), m.GetResources(selector), "no resources should be counted for a cancelled chunk")
 }
+
+// TestResolveResourcesChunk_ChunkResolvers verifies that column and post resource chunk resolvers
+// are called once per chunk, after the row resolvers.
+func TestResolveResourcesChunk_ChunkResolvers(t *testing.T) {
+	var columnCalls, postCalls []int
+	table := &schema.Table{
+		Name: "test_table",
+		Columns: []schema.Column{
+			{
+				Name: "test_column",
+				Type: arrow.PrimitiveTypes.Int64,
+				Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+					return resource.Set(c.Name, int64(resource.Item.(int)))
+				},
+			},
+			{
+				Name: "test_chunk_column",
+				Type: arrow.PrimitiveTypes.Int64,
+				ChunkResolver: &schema.ColumnChunkResolver{
+					ChunkSize: 2,
+					RowsResolver: func(_ context.Context, _ schema.ClientMeta, resources []*schema.Resource, c schema.Column) error {
+						columnCalls = append(columnCalls, len(resources))
+						for _, resource := range resources {
+							// row column resolvers already ran
+							v := resource.Get("test_column").Get().(int64)
+							if err := resource.Set(c.Name, v*10); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				},
+			},
+		},
+		PostResourceChunkResolver: &schema.RowsChunkResolver{
+			ChunkSize: 3,
+			RowsResolver: func(_ context.Context, _ schema.ClientMeta, resources []*schema.Resource) error {
+				postCalls = append(postCalls, len(resources))
+				return nil
+			},
+		},
+	}
+
+	client := testClient{}
+	m := metrics.NewMetrics()
+	m.InitWithClients(table, []schema.ClientMeta{client})
+	logger := zerolog.New(zerolog.NewTestWriter(t))
+
+	resources := ResolveResourcesChunk(context.Background(), logger, m, table, client, nil, []any{0, 1, 2, 3, 4}, caser.New())
+	require.Len(t, resources, 5)
+	for _, r := range resources {
+		require.Equal(t, int64(r.Item.(int)*10), r.Get("test_chunk_column").Get())
+	}
+	require.Equal(t, []int{2, 2, 1}, columnCalls)
+	require.Equal(t, []int{3, 2}, postCalls)
+	require.Zero(t, m.GetErrors(m.NewSelector(client.ID(), table.Name)))
+}
+
+// TestResolveResourcesChunk_RetryPolicy verifies that failed resolver calls are retried with the
+// retry policy, and that the table policy takes precedence over the default one.
+func TestResolveResourcesChunk_RetryPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		tablePolicy   *schema.RetryPolicy
+		defaultPolicy *schema.RetryPolicy
+		wantRetries   uint64
+		wantErrors    uint64
+	}{
+		{
+			name:          "default policy",
+			defaultPolicy: &schema.RetryPolicy{MaxAttempts: 3},
+			wantRetries:   2,
+		},
+		{
+			name:          "table policy takes precedence",
+			tablePolicy:   &schema.RetryPolicy{MaxAttempts: 2},
+			defaultPolicy: &schema.RetryPolicy{MaxAttempts: 3},
+			wantRetries:   1,
+			wantErrors:    1,
+		},
+		{
+			name:       "no policy",
+			wantErrors: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			table := &schema.Table{
+				Name:        "test_table",
+				RetryPolicy: tc.tablePolicy,
+				Columns: []schema.Column{
+					{
+						Name: "test_column",
+						Type: arrow.PrimitiveTypes.Int64,
+						Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+							calls++
+							if calls < 3 {
+								return errors.New("transient")
+							}
+							return resource.Set(c.Name, int64(1))
+						},
+					},
+				},
+			}
+
+			client := testClient{}
+			m := metrics.NewMetrics()
+			m.InitWithClients(table, []schema.ClientMeta{client})
+			logger := zerolog.New(zerolog.NewTestWriter(t))
+
+			ResolveResourcesChunkWithRetryPolicy(context.Background(), logger, m, table, client, nil, []any{0}, caser.New(), nil, tc.defaultPolicy)
+			selector := m.NewSelector(client.ID(), table.Name)
+			require.Equal(t, tc.wantRetries, m.GetRetries(selector))
+			require.Equal(t, tc.wantErrors, m.GetErrors(selector))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_RetryPolicy(t *testing.T) {
+	errTransient := errors.New("transient")
+	// failingTable returns a table whose resolver fails the given number of times before succeeding,
+	// optionally after sending an item
+	failingTable := func(name string, failures int32, sendFirst bool) *schema.Table {
+		table := testTableSuccess()
+		table.Name = name
+		var calls atomic.Int32
+		table.Resolver = func(ctx context.Context, meta schema.ClientMeta, parent *schema.Resource, res chan<- any) error {
+			if calls.Add(1) > failures {
+				return testResolverSuccess(ctx, meta, parent, res)
+			}
+			if sendFirst {
+				if err := testResolverSuccess(ctx, meta, parent, res); err != nil {
+					return err
+				}
+			}
+			return errTransient
+		}
+		return table
+	}
+
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			tables := schema.Tables{
+				failingTable("test_table_recovered", 2, false),
+				failingTable("test_table_exhausted", 5, false),
+				// a call that sent items isn't retried, so that they're not sent twice
+				failingTable("test_table_partial", 1, true),
+				// the table policy overrides the default one
+				failingTable("test_table_no_retry", 1, false),
+			}
+			tables[3].RetryPolicy = &schema.RetryPolicy{}
+
+			sc := NewScheduler(
+				WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+				WithStrategy(strategy),
+				WithRetryPolicy(schema.RetryPolicy{MaxAttempts: 3}),
+			)
+			msgs := make(chan message.SyncMessage, 500)
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, WithSyncSummary()))
+			close(msgs)
+
+			syncErrors := make(map[string]string)
+			var summary *message.SyncSummary
+			for msg := range msgs {
+				switch m := msg.(type) {
+				case *message.SyncError:
+					syncErrors[m.TableName] = m.Error
+				case *message.SyncSummary:
+					summary = m
+				}
+			}
+			require.Equal(t, map[string]string{
+				"test_table_exhausted": "transient (failed after 3 attempts)",
+				"test_table_partial":   "transient",
+				"test_table_no_retry":  "transient",
+			}, syncErrors)
+
+			require.NotNil(t, summary)
+			retries := make(map[string]uint64)
+			resources := make(map[string]uint64)
+			for _, table := range summary.Tables {
+				retries[table.TableName] = table.Clients[0].Retries
+				resources[table.TableName] = table.Clients[0].Resources
+			}
+			require.Equal(t, map[string]uint64{
+				"test_table_recovered": 2,
+				"test_table_exhausted": 2,
+				"test_table_partial":   0,
+				"test_table_no_retry":  0,
+			}, retries)
+			require.Equal(t, map[string]uint64{
+				"test_table_recovered": 1,
+				"test_table_exhausted": 0,
+				"test_table_partial":   1,
+				"test_table_no_retry":  0,
+			}, resources)
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/cloudquery/plugin-sdk/v4/caser"
 	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/scheduler/cache"
 	"github.com/cloudquery/plugin-sdk/v4/scheduler/metrics"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/rs/zerolog"
@@ -35,6 +37,7 @@ const (
 	StrategyRoundRobin
 	StrategyShuffle
 	StrategyShuffleQueue
+	StrategyPriority
 )
 
 // Re-exported from the schema package for use with WithErrorClassifier.
@@ -50,6 +53,7 @@ const (
 	ErrorPhasePreResourceResolver      = schema.ErrorPhasePreResourceResolver
 	ErrorPhaseColumnResolver           = schema.ErrorPhaseColumnResolver
 	ErrorPhasePostResourceResolver     = schema.ErrorPhasePostResourceResolver
+	ErrorPhaseColumnValidation         = schema.ErrorPhaseColumnValidation
 )
 
 type Option func(*Scheduler)
@@ -68,6 +72,13 @@ func WithErrorClassifier(classifier ErrorClassifier) Option {
 	}
 }
 
+// WithRetryPolicy sets the retry policy of the tables that don't have one. See schema.RetryPolicy.
+func WithRetryPolicy(policy schema.RetryPolicy) Option {
+	return func(s *Scheduler) {
+		s.retryPolicy = &policy
+	}
+}
+
 func WithConcurrency(concurrency int) Option {
 	return func(s *Scheduler) {
 		s.concurrency = concurrency
@@ -118,6 +129,16 @@ func WithShard(num int32, total int32) SyncOption {
 	}
 }
 
+// WithResolverCache makes a cache.Cache available to resolvers through their context for the duration of the sync,
+// see cache.Memoize. Values are kept for ttl, up to maxEntries values.
+func WithResolverCache(ttl time.Duration, maxEntries int) SyncOption {
+	return func(s *syncClient) {
+		s.cacheTTL = ttl
+		s.cacheMaxEntries = maxEntries
+		s.cacheEnabled = true
+	}
+}
+
 type Client interface {
 	ID() string
 }
@@ -147,6 +168,19 @@ type Scheduler struct {
 	invocationID string
 
 	errorClassifier schema.ErrorClassifier
+
+	// rateLimiter is consulted before every resolver call
+	rateLimiter RateLimiter
+	// throttleBackoff slows down the table clients whose errors are reported as throttling errors by the classifier
+	throttleMinBackoff time.Duration
+	throttleMaxBackoff time.Duration
+	throttleBackoff    *throttleBackoff
+
+	// retryPolicy is the retry policy of the tables that don't have one
+	retryPolicy *schema.RetryPolicy
+
+	// memoryBudget limits the estimated size in bytes of the buffered resources, 0 means no limit
+	memoryBudget int64
 }
 
 type shard struct {
@@ -167,6 +201,44 @@ type syncClient struct {
 	msgChan chan<- message.SyncMessage
 
 	shard *shard
+
+	// checkpoints skips the table clients completed by a previous sync, and records the ones completed by this one
+	checkpoints *checkpoints
+	// inFlight tracks the resources sent by the resolvers until the batcher sends them
+	inFlight *inFlight
+
+	// budget tracks the tables skipped once the sync deadline passes, if one is set
+	budget *syncBudget
+
+	// summary controls whether a message.SyncSummary is sent at the end of the sync
+	summary bool
+
+	// resolver cache settings, the cache is created for every sync
+	cacheEnabled    bool
+	cacheTTL        time.Duration
+	cacheMaxEntries int
+
+	// incremental tracks the cursors of incremental tables, if enabled
+	incremental *incrementalState
+
+	// record or replay the items sent by table resolvers, replay takes precedence
+	recordPath  string
+	recorder    *recorder
+	replayPath  string
+	replayTypes []any
+	replayer    *replayer
+
+	// dependencies collects the resources of the tables other tables depend on, if any
+	dependencies *dependencies
+
+	// progress tracks the top-level table clients for progress reports, if enabled
+	progress *syncProgress
+
+	// planDepth is the depth of tables resolved in plan mode, plan mode is disabled if it's 0
+	planDepth int
+
+	// tablePriorities overrides the priorities of top-level tables for the priority strategy
+	tablePriorities map[string]int
 }
 
 func NewScheduler(opts ...Option) *Scheduler {
@@ -180,11 +252,19 @@ func NewScheduler(opts ...Option) *Scheduler {
 			MaxRows: DefaultBatchMaxRows,
 			Timeout: DefaultBatchTimeout,
 		},
+		throttleMinBackoff: DefaultThrottleMinBackoff,
+		throttleMaxBackoff: DefaultThrottleMaxBackoff,
 	}
 	for _, opt := range opts {
 		opt(&s)
 	}
 
+	if s.errorClassifier != nil {
+		// only the error classifier reports throttling errors
+		s.throttleBackoff = newThrottleBackoff(s.throttleMinBackoff, s.throttleMaxBackoff)
+		s.errorClassifier = s.throttleBackoff.classifier(s.errorClassifier)
+	}
+
 	actualMinResourceConcurrency := minResourceConcurrency
 	if s.concurrency <= minResourceConcurrency {
 		actualMinResourceConcurrency = max(s.concurrency/10, 1)
@@ -222,7 +302,7 @@ func (s *Scheduler) SyncAll(ctx context.Context, client schema.ClientMeta, table
 	return messages, err
 }
 
-func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables schema.Tables, res chan<- message.SyncMessage, opts ...SyncOption) error {
+func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables schema.Tables, res chan<- message.SyncMessage, opts ...SyncOption) (retErr error) {
 	ctx, span := otel.Tracer(metrics.ResourceName).Start(ctx,
 		"sync",
 		trace.WithAttributes(attribute.Key("sync.invocation.id").String(s.invocationID)),
@@ -249,6 +329,49 @@ func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables s
 		return fmt.Errorf("max depth exceeded, max depth is %d", s.maxDepth)
 	}
 
+	if syncClient.incremental != nil {
+		ctx = context.WithValue(ctx, incrementalContextKey{}, syncClient.incremental)
+	}
+
+	if deps := newDependencies(tables); deps != nil {
+		syncClient.dependencies = deps
+		ctx = context.WithValue(ctx, dependencyContextKey{}, deps)
+	}
+
+	if syncClient.replayPath != "" {
+		replayer, err := newReplayer(syncClient.replayPath, syncClient.replayTypes)
+		if err != nil {
+			return err
+		}
+		syncClient.replayer = replayer
+	} else if syncClient.recordPath != "" {
+		recorder, err := newRecorder(syncClient.recordPath)
+		if err != nil {
+			return err
+		}
+		syncClient.recorder = recorder
+		defer func() {
+			if err := recorder.close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+	}
+	if syncClient.replayer != nil || syncClient.recorder != nil {
+		syncClient.tables = syncClient.wrapResolvers(syncClient.tables)
+	}
+
+	if syncClient.cacheEnabled {
+		ctx = cache.NewContext(ctx, cache.New(syncClient.cacheTTL, syncClient.cacheMaxEntries, syncClient.metrics))
+		defer func() {
+			s.logger.Info().Uint64("hits", syncClient.metrics.GetCacheHits()).Uint64("misses", syncClient.metrics.GetCacheMisses()).Msg("resolver cache stats")
+		}()
+	}
+
+	if syncClient.planDepth > 0 {
+		syncClient.syncPlan(ctx)
+		return context.Cause(ctx)
+	}
+
 	// send migrate messages first
 	for _, tableOriginal := range tables.FlattenTables() {
 		migrateMessage := &message.SyncMigrateTable{
@@ -260,9 +383,14 @@ func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables s
 		res <- migrateMessage
 	}
 
+	syncClient.inFlight = newInFlight(syncClient.checkpoints, newMemoryBudget(s.memoryBudget))
+	// created before the resolvers start, as they flush it when the memory budget is used up
+	b := s.batchSettings.getBatcher(ctx, res, s.logger, syncClient.inFlight)
+
 	resources := make(chan *schema.Resource)
 	go func() {
 		defer close(resources)
+		defer syncClient.reportSkipped()
 		testMultiplier, err := getTestMultiplier()
 		if err != nil {
 			panic(err)
@@ -271,21 +399,64 @@ func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables s
 			syncClient.syncTest(ctx, testMultiplier, resources)
 			return
 		}
-		switch s.strategy {
-		case StrategyDFS:
-			syncClient.syncDfs(ctx, resources)
-		case StrategyRoundRobin:
-			syncClient.syncRoundRobin(ctx, resources)
-		case StrategyShuffle:
-			syncClient.syncShuffle(ctx, resources)
-		case StrategyShuffleQueue:
-			syncClient.syncShuffleQueue(ctx, resources)
-		default:
-			panic(fmt.Errorf("unknown scheduler %s", s.strategy.String()))
-		}
+		syncClient.syncStages(resources, func(resolvedResources chan<- *schema.Resource) {
+			switch s.strategy {
+			case StrategyDFS:
+				syncClient.syncDfs(ctx, resolvedResources)
+			case StrategyRoundRobin:
+				syncClient.syncRoundRobin(ctx, resolvedResources)
+			case StrategyShuffle:
+				syncClient.syncShuffle(ctx, resolvedResources)
+			case StrategyShuffleQueue:
+				syncClient.syncShuffleQueue(ctx, resolvedResources)
+			case StrategyPriority:
+				syncClient.syncPriority(ctx, resolvedResources)
+			default:
+				panic(fmt.Errorf("unknown scheduler %s", s.strategy.String()))
+			}
+		})
 	}()
 
-	b := s.batchSettings.getBatcher(ctx, res, s.logger)
+	if syncClient.summary {
+		// deferred before the batcher is closed so that the summary is sent after the last insert
+		defer func() {
+			select {
+			case res <- syncClient.syncSummary():
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	if syncClient.incremental != nil {
+		// deferred before the batcher is closed so that cursors are committed after the last insert was sent
+		defer func() {
+			if retErr != nil || ctx.Err() != nil || syncClient.budget.incomplete() {
+				return
+			}
+			retErr = syncClient.incremental.commit(ctx, s.logger, func(tableName, clientID string) bool {
+				selector := syncClient.metrics.NewSelector(clientID, tableName)
+				return syncClient.metrics.GetErrors(selector) == 0 && syncClient.metrics.GetPanics(selector) == 0
+			})
+		}()
+	}
+
+	if syncClient.checkpoints != nil {
+		// deferred before the batcher is closed so that the last checkpoints are acknowledged before they're stored
+		stop := syncClient.checkpoints.start(ctx)
+		defer func() {
+			stop()
+			if retErr == nil && ctx.Err() == nil && !syncClient.budget.incomplete() {
+				retErr = syncClient.checkpoints.clear(ctx)
+				return
+			}
+			// the sync was interrupted, store what was completed so far so that the next sync can resume
+			syncClient.checkpoints.store(context.WithoutCancel(ctx))
+		}()
+	}
+
+	// stopped after the batcher is closed and before the summary is sent
+	defer syncClient.startProgress(ctx)()
+
 	defer b.close()    // wait for all resources to be processed
 	done := ctx.Done() // no need to do the lookups in loop
 	for resource := range resources {
@@ -295,6 +466,7 @@ func (s *Scheduler) Sync(ctx context.Context, client schema.ClientMeta, tables s
 			return context.Cause(ctx)
 		default:
 			b.process(resource)
+			syncClient.progress.resolved()
 		}
 	}
 	return context.Cause(ctx)
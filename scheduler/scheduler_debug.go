@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -68,13 +69,18 @@ func (s *syncClient) syncTest(ctx context.Context, syncMultiplier int, resolvedR
 	}
 	shuffle(allClients, seed)
 	allClients = shardTableClients(allClients, s.shard)
+	allClients = s.skipCompleted(ctx, allClients)
+	s.progress.plan(allClients)
 
 	var wg sync.WaitGroup
-	for _, tc := range allClients {
+	for i, tc := range allClients {
 		table := tc.table
 		cl := tc.client
-		if err := s.scheduler.tableSems[0].Acquire(ctx, 1); err != nil {
-			// This means context was cancelled
+		if err := s.acquireStart(ctx, s.scheduler.tableSems[0]); err != nil {
+			if errors.Is(err, errSyncDeadlineExceeded) {
+				s.budget.skipAll(allClients[i:])
+			}
+			// This means context was cancelled or the sync deadline passed
 			wg.Wait()
 			return
 		}
@@ -87,6 +93,7 @@ func (s *syncClient) syncTest(ctx context.Context, syncMultiplier int, resolvedR
 			// This currently uses the DFS algorithm to resolve the tables, but this
 			// may change in the future.
 			s.resolveTableDfs(ctx, table, cl, nil, resolvedResources, 1)
+			s.markCompleted(ctx, table, cl)
 		}()
 	}
 
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sync"
@@ -52,13 +53,18 @@ func (s *syncClient) syncDfs(ctx context.Context, resolvedResources chan<- *sche
 		}
 	}
 	tableClients = shardTableClients(tableClients, s.shard)
+	tableClients = s.skipCompleted(ctx, tableClients)
+	s.progress.plan(tableClients)
 
 	var wg sync.WaitGroup
-	for _, tc := range tableClients {
+	for i, tc := range tableClients {
 		table := tc.table
 		cl := tc.client
-		if err := s.scheduler.tableSems[0].Acquire(ctx, 1); err != nil {
-			// This means context was cancelled
+		if err := s.acquireStart(ctx, s.scheduler.tableSems[0]); err != nil {
+			if errors.Is(err, errSyncDeadlineExceeded) {
+				s.budget.skipAll(tableClients[i:])
+			}
+			// This means context was cancelled or the sync deadline passed
 			wg.Wait()
 			return
 		}
@@ -71,6 +77,7 @@ func (s *syncClient) syncDfs(ctx context.Context, resolvedResources chan<- *sche
 			// Round Robin currently uses the DFS algorithm to resolve the tables, but this
 			// may change in the future.
 			s.resolveTableDfs(ctx, table, cl, nil, resolvedResources, 1)
+			s.markCompleted(ctx, table, cl)
 		}()
 	}
 
@@ -94,6 +101,7 @@ func (s *syncClient) resolveTableDfs(ctx context.Context, table *schema.Table, c
 	startTime := time.Now()
 	if parent == nil { // Log only for root tables, otherwise we spam too much.
 		logger.Info().Msg("top level table resolver started")
+		s.progress.started(table)
 	}
 	selector := s.metrics.NewSelector(clientName, table.Name)
 	s.metrics.StartTime(startTime, selector)
@@ -127,18 +135,23 @@ func (s *syncClient) resolveTableDfs(ctx context.Context, table *schema.Table, c
 			}
 			close(res)
 		}()
-		if err := table.Resolver(ctx, client, parent, res); err != nil {
+		if err := s.scheduler.waitRateLimit(ctx, clientName, table.Name); err != nil {
+			logger.Debug().Err(err).Msg("table resolver cancelled while waiting for rate limit")
+			return
+		}
+		retries, err := resolvers.RetryTableResolver(ctx, logger, s.metrics, resolvers.RetryPolicy(table, s.scheduler.retryPolicy), table, client, parent, res)
+		if err != nil {
 			event := schema.ErrorEvent{Table: table, Client: client, Phase: schema.ErrorPhaseTableResolver}
 			if s.scheduler.errorClassifier.Suppress(ctx, err, event) {
 				logger.Debug().Err(err).Msg("table resolver finished with suppressed error")
 				return
 			}
-			logger.Error().Err(err).Msg("table resolver finished with error")
-			s.metrics.AddErrors(ctx, 1, selector)
+			logger.Error().Err(err).Int("retries", retries).Msg("table resolver finished with error")
+			s.metrics.AddError(ctx, err, selector)
 			// Send SyncError message
 			syncErrorMsg := &message.SyncError{
 				TableName: table.Name,
-				Error:     err.Error(),
+				Error:     resolvers.RetriedErrorMessage(err, retries),
 			}
 			s.msgChan <- syncErrorMsg
 			return
@@ -174,7 +187,7 @@ func (s *syncClient) resolveResourcesDfs(ctx context.Context, table *schema.Tabl
 		}
 		for i := range chunks {
 			resourceConcurrencyKey := table.Name + "-" + client.ID() + "-" + "resource"
-			resourceSemVal, _ := s.scheduler.singleTableConcurrency.LoadOrStore(resourceConcurrencyKey, semaphore.NewWeighted(s.scheduler.singleResourceMaxConcurrency))
+			resourceSemVal, _ := s.scheduler.singleTableConcurrency.LoadOrStore(resourceConcurrencyKey, semaphore.NewWeighted(s.scheduler.singleResourceMaxConcurrency*s.concurrencyWeight(table)))
 			resourceSem := resourceSemVal.(*semaphore.Weighted)
 			if err := resourceSem.Acquire(ctx, 1); err != nil {
 				s.logger.Warn().Err(err).Msg("failed to acquire semaphore. context cancelled")
@@ -197,7 +210,11 @@ func (s *syncClient) resolveResourcesDfs(ctx context.Context, table *schema.Tabl
 				defer resourceSem.Release(1)
 				defer s.scheduler.resourceSem.Release(1)
 				defer wg.Done()
-				resolvedResources := resolvers.ResolveResourcesChunkWithClassifier(ctx, s.logger, s.metrics, table, client, parent, chunks[i], s.scheduler.caser, s.scheduler.errorClassifier)
+				if err := s.scheduler.waitRateLimit(ctx, client.ID(), table.Name); err != nil {
+					s.logger.Debug().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver cancelled while waiting for rate limit")
+					return
+				}
+				resolvedResources := resolvers.ResolveResourcesChunkWithRetryPolicy(ctx, s.logger, s.metrics, table, client, parent, chunks[i], s.scheduler.caser, s.scheduler.errorClassifier, s.scheduler.retryPolicy)
 				if len(resolvedResources) == 0 {
 					return
 				}
@@ -205,20 +222,25 @@ func (s *syncClient) resolveResourcesDfs(ctx context.Context, table *schema.Tabl
 				for _, resolvedResource := range resolvedResources {
 					if err := resolvedResource.CalculateCQID(s.deterministicCQID); err != nil {
 						s.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with primary key calculation error")
-						s.metrics.AddErrors(ctx, 1, selector)
+						s.metrics.AddError(ctx, err, selector)
 						return
 					}
 					if err := resolvedResource.StoreCQClientID(client.ID()); err != nil {
 						s.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("failed to store _cq_client_id")
 					}
 					if err := resolvedResource.Validate(); err != nil {
-						switch err.(type) {
-						case *schema.PKError:
+						var pkErr *schema.PKError
+						if errors.As(err, &pkErr) {
 							s.logger.Error().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation error")
-							s.metrics.AddErrors(ctx, 1, selector)
+							s.metrics.AddError(ctx, err, selector)
 							return
-						case *schema.PKComponentError:
-							s.logger.Warn().Err(err).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation warning")
+						}
+						var pkComponentErr *schema.PKComponentError
+						if errors.As(err, &pkComponentErr) {
+							s.logger.Warn().Err(pkComponentErr).Str("table", table.Name).Str("client", client.ID()).Msg("resource resolver finished with validation warning")
+						}
+						if resolvers.HandleColumnValidationErrors(ctx, s.logger, s.metrics, selector, client, resolvedResource, err, s.scheduler.errorClassifier) {
+							continue
 						}
 					}
 					select {
@@ -234,22 +256,33 @@ func (s *syncClient) resolveResourcesDfs(ctx context.Context, table *schema.Tabl
 	var wg sync.WaitGroup
 	for resource := range resourcesChan {
 		resource := resource
+		s.incremental.observe(resource, client.ID())
+		s.inFlight.add(ctx, resource, client)
 		resolvedResources <- resource
 		for _, relation := range resource.Table.Relations {
 			relation := relation
 			tableConcurrencyKey := table.Name + "-" + client.ID()
 			// Acquire the semaphore for the table
-			tableSemVal, _ := s.scheduler.singleTableConcurrency.LoadOrStore(tableConcurrencyKey, semaphore.NewWeighted(s.scheduler.singleNestedTableMaxConcurrency))
+			tableSemVal, _ := s.scheduler.singleTableConcurrency.LoadOrStore(tableConcurrencyKey, semaphore.NewWeighted(s.scheduler.singleNestedTableMaxConcurrency*s.concurrencyWeight(table)))
 			tableSem := tableSemVal.(*semaphore.Weighted)
-			if err := tableSem.Acquire(ctx, 1); err != nil {
+			if err := s.acquireStart(ctx, tableSem); err != nil {
+				if errors.Is(err, errSyncDeadlineExceeded) {
+					// keep draining the resources so that the remaining relations are recorded as skipped
+					s.budget.skip(relation, client, table)
+					continue
+				}
 				// This means context was cancelled
 				wg.Wait()
 				return
 			}
 			// Once table semaphore is acquired we can acquire the global semaphore
-			if err := s.scheduler.tableSems[depth].Acquire(ctx, 1); err != nil {
-				// This means context was cancelled
+			if err := s.acquireStart(ctx, s.scheduler.tableSems[depth]); err != nil {
 				tableSem.Release(1)
+				if errors.Is(err, errSyncDeadlineExceeded) {
+					s.budget.skip(relation, client, table)
+					continue
+				}
+				// This means context was cancelled
 				wg.Wait()
 				return
 			}
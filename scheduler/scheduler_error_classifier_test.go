@@ -92,3 +92,76 @@ func syncErrors(t *testing.T, strategy Strategy, table *schema.Table, classifier
 	}
 	return syncErrs
 }
+
+// TestSchedulerColumnValidation verifies that resources rejected by a column validator are dropped, unless the
+// ErrorClassifier suppresses the violation.
+func TestSchedulerColumnValidation(t *testing.T) {
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			table := &schema.Table{
+				Name: "test_table",
+				Resolver: func(_ context.Context, _ schema.ClientMeta, _ *schema.Resource, res chan<- any) error {
+					res <- []string{"running", "unknown"}
+					return nil
+				},
+				Columns: []schema.Column{
+					{
+						Name: "state",
+						Type: arrow.BinaryTypes.String,
+						Resolver: func(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+							return resource.Set(c.Name, resource.Item)
+						},
+						Validators: []schema.ColumnValidator{schema.EnumValidator("running", "stopped")},
+					},
+				},
+			}
+
+			t.Run("dropped by default", func(t *testing.T) {
+				require.Equal(t, 1, syncColumnValidation(t, strategy, table, nil))
+			})
+
+			t.Run("kept when suppressed by classifier", func(t *testing.T) {
+				var seen []schema.ErrorEvent
+				var mu sync.Mutex
+				classifier := func(_ context.Context, err error, event schema.ErrorEvent) bool {
+					mu.Lock()
+					seen = append(seen, event)
+					mu.Unlock()
+					var validationErr *schema.ColumnValidationError
+					return errors.As(err, &validationErr)
+				}
+				require.Equal(t, 2, syncColumnValidation(t, strategy, table, classifier))
+
+				mu.Lock()
+				defer mu.Unlock()
+				require.Len(t, seen, 1)
+				require.Equal(t, schema.ErrorPhaseColumnValidation, seen[0].Phase)
+				require.Equal(t, "state", seen[0].Column.Name)
+			})
+		})
+	}
+}
+
+func syncColumnValidation(t *testing.T, strategy Strategy, table *schema.Table, classifier schema.ErrorClassifier) int {
+	t.Helper()
+	opts := []Option{
+		WithLogger(zerolog.New(zerolog.NewTestWriter(t)).Level(zerolog.DebugLevel)),
+		WithStrategy(strategy),
+	}
+	if classifier != nil {
+		opts = append(opts, WithErrorClassifier(classifier))
+	}
+	sc := NewScheduler(opts...)
+
+	msgs := make(chan message.SyncMessage, 100)
+	require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, schema.Tables{table}, msgs))
+	close(msgs)
+
+	inserts := 0
+	for msg := range msgs {
+		if m, ok := msg.(*message.SyncInsert); ok {
+			inserts += int(m.Record.NumRows())
+		}
+	}
+	return inserts
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// WithTablePriorities overrides the priorities of top-level tables, by table name, for the priority strategy.
+// Tables missing from the map use their schema.Table Priority.
+func WithTablePriorities(priorities map[string]int) SyncOption {
+	return func(s *syncClient) {
+		s.tablePriorities = priorities
+	}
+}
+
+func (s *syncClient) syncPriority(ctx context.Context, resolvedResources chan<- *schema.Resource) {
+	// we have this because plugins can return sometimes clients in a random way which will cause
+	// differences between this run and the next one.
+	preInitialisedClients := make([][]schema.ClientMeta, len(s.tables))
+	for i, table := range s.tables {
+		clients := []schema.ClientMeta{s.client}
+		if table.Multiplex != nil {
+			clients = table.Multiplex(s.client)
+		}
+		// Detect duplicate clients while multiplexing
+		seenClients := make(map[string]bool)
+		for _, c := range clients {
+			if _, ok := seenClients[c.ID()]; !ok {
+				seenClients[c.ID()] = true
+			} else {
+				s.logger.Warn().Str("client", c.ID()).Str("table", table.Name).Msg("multiplex returned duplicate client")
+			}
+		}
+		preInitialisedClients[i] = clients
+		// we do this here to avoid locks so we initial the metrics structure once in the main goroutines
+		// and then we can just read from it in the other goroutines concurrently given we are not writing to it.
+		s.metrics.InitWithClients(table, clients)
+	}
+
+	// Interleave the tables like in round-robin, then move higher priority tables to the front.
+	// The sort is stable so tables with the same priority keep the round-robin order.
+	tableClients := roundRobinInterleave(s.tables, preInitialisedClients)
+	tableClients = shardTableClients(tableClients, s.shard)
+	tableClients = s.skipCompleted(ctx, tableClients)
+	s.progress.plan(tableClients)
+	slices.SortStableFunc(tableClients, func(a, b tableClient) int {
+		return cmp.Compare(s.tablePriority(b.table), s.tablePriority(a.table))
+	})
+
+	var wg sync.WaitGroup
+	for i, tc := range tableClients {
+		table := tc.table
+		cl := tc.client
+		if err := s.acquireStart(ctx, s.scheduler.tableSems[0]); err != nil {
+			if errors.Is(err, errSyncDeadlineExceeded) {
+				s.budget.skipAll(tableClients[i:])
+			}
+			// This means context was cancelled or the sync deadline passed
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.scheduler.tableSems[0].Release(1)
+			// not checking for error here as nothing much to do.
+			// the error is logged and this happens when context is cancelled
+			s.resolveTableDfs(ctx, table, cl, nil, resolvedResources, 1)
+			s.markCompleted(ctx, table, cl)
+		}()
+	}
+
+	// Wait for all the worker goroutines to finish
+	wg.Wait()
+}
+
+// tablePriority returns the priority of the top-level table the given table belongs to.
+func (s *syncClient) tablePriority(table *schema.Table) int {
+	for table.Parent != nil {
+		table = table.Parent
+	}
+	if priority, ok := s.tablePriorities[table.Name]; ok {
+		return priority
+	}
+	return table.Priority
+}
+
+// concurrencyWeight returns the multiplier applied to the per-table concurrency limits of the table.
+// With the priority strategy, tables with a positive priority P get P+1 times the default limits.
+func (s *syncClient) concurrencyWeight(table *schema.Table) int64 {
+	if s.scheduler.strategy != StrategyPriority {
+		return 1
+	}
+	return int64(max(s.tablePriority(table), 0)) + 1
+}
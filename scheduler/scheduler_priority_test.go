@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncPriority(t *testing.T) {
+	cases := []struct {
+		name       string
+		priorities map[string]int
+		want       []string
+	}{
+		{
+			name: "table priorities",
+			want: []string{"table_high", "table_low", "table_default", "table_default2"},
+		},
+		{
+			name:       "sync option overrides table priorities",
+			priorities: map[string]int{"table_default2": 10, "table_high": -1},
+			want:       []string{"table_default2", "table_low", "table_default", "table_high"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var got []string
+			newTable := func(name string, priority int) *schema.Table {
+				return &schema.Table{
+					Name:     name,
+					Priority: priority,
+					Resolver: func(context.Context, schema.ClientMeta, *schema.Resource, chan<- any) error {
+						mu.Lock()
+						defer mu.Unlock()
+						got = append(got, name)
+						return nil
+					},
+				}
+			}
+			tables := schema.Tables{
+				newTable("table_default", 0),
+				newTable("table_low", 1),
+				newTable("table_default2", 0),
+				newTable("table_high", 5),
+			}
+
+			// a concurrency of 1 resolves the top-level tables one at a time
+			sc := NewScheduler(
+				WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+				WithStrategy(StrategyPriority),
+				WithConcurrency(1),
+			)
+			msgs := make(chan message.SyncMessage, 500)
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, WithTablePriorities(tc.priorities)))
+			close(msgs)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConcurrencyWeight(t *testing.T) {
+	parent := &schema.Table{Name: "parent", Priority: 2}
+	child := &schema.Table{Name: "child", Parent: parent}
+	negative := &schema.Table{Name: "negative", Priority: -3}
+
+	s := &syncClient{scheduler: NewScheduler(WithStrategy(StrategyPriority))}
+	require.EqualValues(t, 3, s.concurrencyWeight(parent))
+	require.EqualValues(t, 3, s.concurrencyWeight(child))
+	require.EqualValues(t, 1, s.concurrencyWeight(negative))
+
+	s = &syncClient{scheduler: NewScheduler(WithStrategy(StrategyDFS))}
+	require.EqualValues(t, 1, s.concurrencyWeight(parent))
+}
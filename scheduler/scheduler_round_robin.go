@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"sync"
 
 	"github.com/cloudquery/plugin-sdk/v4/schema"
@@ -38,13 +39,18 @@ func (s *syncClient) syncRoundRobin(ctx context.Context, resolvedResources chan<
 
 	tableClients := roundRobinInterleave(s.tables, preInitialisedClients)
 	tableClients = shardTableClients(tableClients, s.shard)
+	tableClients = s.skipCompleted(ctx, tableClients)
+	s.progress.plan(tableClients)
 
 	var wg sync.WaitGroup
-	for _, tc := range tableClients {
+	for i, tc := range tableClients {
 		table := tc.table
 		cl := tc.client
-		if err := s.scheduler.tableSems[0].Acquire(ctx, 1); err != nil {
-			// This means context was cancelled
+		if err := s.acquireStart(ctx, s.scheduler.tableSems[0]); err != nil {
+			if errors.Is(err, errSyncDeadlineExceeded) {
+				s.budget.skipAll(tableClients[i:])
+			}
+			// This means context was cancelled or the sync deadline passed
 			wg.Wait()
 			return
 		}
@@ -57,6 +63,7 @@ func (s *syncClient) syncRoundRobin(ctx context.Context, resolvedResources chan<
 			// Round Robin currently uses the DFS algorithm to resolve the tables, but this
 			// may change in the future.
 			s.resolveTableDfs(ctx, table, cl, nil, resolvedResources, 1)
+			s.markCompleted(ctx, table, cl)
 		}()
 	}
 
@@ -26,6 +26,8 @@ func (s *syncClient) syncShuffleQueue(ctx context.Context, resolvedResources cha
 
 	tableClients := roundRobinInterleave(s.tables, preInitialisedClients)
 	tableClients = shardTableClients(tableClients, s.shard)
+	tableClients = s.skipCompleted(ctx, tableClients)
+	s.progress.plan(tableClients)
 	seed := hashTableNames(tableNames)
 	shuffle(tableClients, seed)
 
@@ -38,6 +40,29 @@ func (s *syncClient) syncShuffleQueue(ctx context.Context, resolvedResources cha
 		queue.WithDeterministicCQID(s.deterministicCQID),
 		queue.WithInvocationID(s.invocationID),
 		queue.WithErrorClassifier(s.scheduler.errorClassifier),
+		queue.WithRateLimitFunc(s.scheduler.waitRateLimit),
+		queue.WithRetryPolicy(s.scheduler.retryPolicy),
+		queue.WithResolvedFunc(func(resource *schema.Resource, client schema.ClientMeta) {
+			s.incremental.observe(resource, client.ID())
+			s.inFlight.add(ctx, resource, client)
+		}),
+		queue.WithSkipFunc(func(table *schema.Table, client schema.ClientMeta, parent *schema.Resource) bool {
+			if !s.budget.exceeded() {
+				return false
+			}
+			var parentTable *schema.Table
+			if parent != nil {
+				parentTable = parent.Table
+			}
+			s.budget.skip(table, client, parentTable)
+			return true
+		}),
+		queue.WithTopLevelStartFunc(func(table *schema.Table, _ schema.ClientMeta) {
+			s.progress.started(table)
+		}),
+		queue.WithTopLevelDoneFunc(func(table *schema.Table, client schema.ClientMeta) {
+			s.markCompleted(ctx, table, client)
+		}),
 	)
 	queueClients := make([]queue.WorkUnit, 0, len(tableClients))
 	for _, tc := range tableClients {
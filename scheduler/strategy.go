@@ -70,12 +70,13 @@ func (Strategy) JSONSchema() *jsonschema.Schema {
 	}
 }
 
-var AllStrategies = Strategies{StrategyDFS, StrategyRoundRobin, StrategyShuffle, StrategyShuffleQueue}
+var AllStrategies = Strategies{StrategyDFS, StrategyRoundRobin, StrategyShuffle, StrategyShuffleQueue, StrategyPriority}
 var AllStrategyNames = [...]string{
 	StrategyDFS:          "dfs",
 	StrategyRoundRobin:   "round-robin",
 	StrategyShuffle:      "shuffle",
 	StrategyShuffleQueue: "shuffle-queue",
+	StrategyPriority:     "priority",
 }
 
 func StrategyForName(s string) (Strategy, error) {
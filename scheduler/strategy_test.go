@@ -38,6 +38,14 @@ func TestStrategy_JSONSchema(t *testing.T) {
 			Name: "shuffle scheduler",
 			Spec: `"shuffle"`,
 		},
+		{
+			Name: "shuffle-queue scheduler",
+			Spec: `"shuffle-queue"`,
+		},
+		{
+			Name: "priority scheduler",
+			Spec: `"priority"`,
+		},
 		{
 			Name: "empty scheduler",
 			Err:  true,
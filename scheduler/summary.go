@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"github.com/cloudquery/plugin-sdk/v4/message"
+)
+
+// WithSyncSummary makes the scheduler send a message.SyncSummary with the per-table and per-client statistics
+// of the sync as the last message, including when the sync is cut short by a deadline.
+func WithSyncSummary() SyncOption {
+	return func(s *syncClient) {
+		s.summary = true
+	}
+}
+
+// syncSummary builds the summary of all the tables in this sync, including relations, in the order they were given.
+func (s *syncClient) syncSummary() *message.SyncSummary {
+	tables := s.tables.FlattenTables()
+	summary := &message.SyncSummary{Tables: make([]message.TableSyncSummary, 0, len(tables))}
+	for _, table := range tables {
+		clientIDs := s.metrics.ClientIDs(table.Name)
+		if len(clientIDs) == 0 {
+			// the table was never initialized, e.g. because the sync was cancelled before it started
+			continue
+		}
+		tableSummary := message.TableSyncSummary{
+			TableName: table.Name,
+			Duration:  s.metrics.TableDuration(table.Name),
+			Clients:   make([]message.ClientSyncSummary, 0, len(clientIDs)),
+		}
+		for _, clientID := range clientIDs {
+			selector := s.metrics.NewSelector(clientID, table.Name)
+			tableSummary.Clients = append(tableSummary.Clients, message.ClientSyncSummary{
+				ClientID:     clientID,
+				Resources:    s.metrics.GetResources(selector),
+				Errors:       s.metrics.GetErrors(selector),
+				Panics:       s.metrics.GetPanics(selector),
+				Retries:      s.metrics.GetRetries(selector),
+				Duration:     s.metrics.GetDuration(selector),
+				ErrorSamples: s.metrics.GetErrorSamples(selector),
+			})
+		}
+		summary.Tables = append(summary.Tables, tableSummary)
+	}
+	return summary
+}
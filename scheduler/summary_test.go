@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_SyncSummary(t *testing.T) {
+	failing := &schema.Table{
+		Name: "test_table_failing",
+		Resolver: func(context.Context, schema.ClientMeta, *schema.Resource, chan<- any) error {
+			return errors.New("failed to list resources")
+		},
+	}
+	for _, strategy := range AllStrategies {
+		t.Run(strategy.String(), func(t *testing.T) {
+			sc := NewScheduler(
+				WithLogger(zerolog.New(zerolog.NewTestWriter(t))),
+				WithStrategy(strategy),
+			)
+			msgs := make(chan message.SyncMessage, 500)
+			tables := schema.Tables{testTableRelationSuccess(), failing}
+			require.NoError(t, sc.Sync(context.Background(), &testExecutionClient{}, tables, msgs, WithSyncSummary()))
+			close(msgs)
+
+			var all []message.SyncMessage
+			for msg := range msgs {
+				all = append(all, msg)
+			}
+			require.NotEmpty(t, all)
+			summary, ok := all[len(all)-1].(*message.SyncSummary)
+			require.True(t, ok, "expected the summary to be the last message, got %T", all[len(all)-1])
+
+			require.Len(t, summary.Tables, 3)
+			require.EqualValues(t, 2, summary.TotalResources())
+			require.EqualValues(t, 1, summary.TotalErrors())
+
+			byName := make(map[string]message.TableSyncSummary)
+			for _, table := range summary.Tables {
+				require.Len(t, table.Clients, 1)
+				require.Equal(t, "test", table.Clients[0].ClientID)
+				byName[table.TableName] = table
+			}
+			require.EqualValues(t, 1, byName["test_table_relation_success"].Clients[0].Resources)
+			require.EqualValues(t, 1, byName["test_table_success"].Clients[0].Resources)
+			require.Equal(t, []string{"failed to list resources"}, byName["test_table_failing"].Clients[0].ErrorSamples)
+		})
+	}
+}
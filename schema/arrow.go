@@ -13,22 +13,33 @@ import (
 )
 
 const (
-	MetadataUnique              = "cq:extension:unique"
-	MetadataPrimaryKey          = "cq:extension:primary_key"
-	MetadataPrimaryKeyComponent = "cq:extension:primary_key_component"
-	MetadataConstraintName      = "cq:extension:constraint_name"
-	MetadataIncremental         = "cq:extension:incremental"
-	MetadataTypeSchema          = "cq:extension:type_schema"
-
-	MetadataTrue                   = "true"
-	MetadataFalse                  = "false"
-	MetadataTableName              = "cq:table_name"
-	MetadataTableDescription       = "cq:table_description"
-	MetadataTableTitle             = "cq:table_title"
-	MetadataTableDependsOn         = "cq:table_depends_on"
-	MetadataTableIsPaid            = "cq:table_paid"
-	MetadataTablePermissionsNeeded = "cq:table_permissions_needed"
-	MetadataTableSensitiveColumns  = "cq:table_sensitive_columns"
+	MetadataUnique               = "cq:extension:unique"
+	MetadataPrimaryKey           = "cq:extension:primary_key"
+	MetadataPrimaryKeyComponent  = "cq:extension:primary_key_component"
+	MetadataConstraintName       = "cq:extension:constraint_name"
+	MetadataIncremental          = "cq:extension:incremental"
+	MetadataTypeSchema           = "cq:extension:type_schema"
+	MetadataDeprecated           = "cq:extension:deprecated"
+	MetadataDeprecationMessage   = "cq:extension:deprecation_message"
+	MetadataRemovedIn            = "cq:extension:removed_in"
+	MetadataPreviousNames        = "cq:extension:previous_names"
+	MetadataPartitionKey         = "cq:extension:partition_key"
+	MetadataPartitionGranularity = "cq:extension:partition_granularity"
+
+	MetadataTrue                    = "true"
+	MetadataFalse                   = "false"
+	MetadataTableName               = "cq:table_name"
+	MetadataTableDescription        = "cq:table_description"
+	MetadataTableTitle              = "cq:table_title"
+	MetadataTableDependsOn          = "cq:table_depends_on"
+	MetadataTableIsPaid             = "cq:table_paid"
+	MetadataTablePermissionsNeeded  = "cq:table_permissions_needed"
+	MetadataTableSensitiveColumns   = "cq:table_sensitive_columns"
+	MetadataTableDeprecated         = "cq:table_deprecated"
+	MetadataTableDeprecationMessage = "cq:table_deprecation_message"
+	MetadataTableRemovedIn          = "cq:table_removed_in"
+	MetadataTableSortKeys           = "cq:table_sort_keys"
+	MetadataTableIndexes            = "cq:table_indexes"
 )
 
 type Schemas []*arrow.Schema
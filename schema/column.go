@@ -15,6 +15,15 @@ type ColumnList []Column
 // resource holds the current row we are resolving the column for.
 type ColumnResolver func(ctx context.Context, meta ClientMeta, resource *Resource, c Column) error
 
+// EXPERIMENTAL: ColumnChunkResolver API might change in future versions of the SDK
+//
+// ColumnChunkResolver resolves a column for chunks of up to ChunkSize rows at once,
+// allowing a single API call to enrich many rows.
+type ColumnChunkResolver struct {
+	ChunkSize    int
+	RowsResolver func(ctx context.Context, meta ClientMeta, resourcesChunk []*Resource, c Column) error
+}
+
 // Column definition for Table
 type Column struct {
 	// Name of column
@@ -25,6 +34,11 @@ type Column struct {
 	Description string `json:"description"`
 	// Column Resolver allows to set your own data for a column; this can be an API call, setting multiple embedded values, etc
 	Resolver ColumnResolver `json:"-"`
+	// ChunkResolver resolves the column for chunks of rows, after all the row column resolvers of the table ran.
+	// It takes precedence over Resolver.
+	ChunkResolver *ColumnChunkResolver `json:"-"`
+	// Validators are run by Resource.Validate on the non-null value of the column, after all the resolvers ran.
+	Validators []ColumnValidator `json:"-"`
 
 	// IgnoreInTests is used to skip verifying the column is non-nil in integration tests.
 	// By default, integration tests perform a fetch for all resources in cloudquery's test account, and
@@ -52,6 +66,25 @@ type Column struct {
 
 	// Some PK columns can be nullable if there are a part of a composite PK, so we want to skip validation for them.
 	SkipPKValidation bool `json:"skip_pk_validation"`
+
+	// Deprecated marks the column as deprecated: it's still synced, but is going to be removed or renamed.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// DeprecationMessage explains the deprecation, e.g. which column to use instead.
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+	// RemovedIn is the plugin version the column is going to be removed in, e.g. "v5.0.0".
+	// Plugin validation fails once the plugin version reaches it.
+	RemovedIn string `json:"removed_in,omitempty"`
+
+	// PartitionKey hints destinations supporting this to partition the table by this column.
+	PartitionKey bool `json:"partition_key,omitempty"`
+	// PartitionGranularity is the time unit the values of a temporal partition key column are truncated to, to
+	// partition the table. Destinations pick their own granularity if it's empty.
+	PartitionGranularity PartitionGranularity `json:"partition_granularity,omitempty"`
+
+	// PreviousNames lists the names the column had in earlier versions of the table, most recent first.
+	// Table.GetChanges with WithColumnRenames reports a rename instead of a removal and an addition when an old column
+	// has one of them.
+	PreviousNames []string `json:"previous_names,omitempty"`
 }
 
 // NewColumnFromArrowField creates a new Column from an arrow.Field
@@ -79,6 +112,22 @@ func NewColumnFromArrowField(f arrow.Field) Column {
 	v, _ = f.Metadata.GetValue(MetadataTypeSchema)
 	column.TypeSchema = v
 
+	v, ok = f.Metadata.GetValue(MetadataDeprecated)
+	column.Deprecated = ok && v == MetadataTrue
+
+	column.DeprecationMessage, _ = f.Metadata.GetValue(MetadataDeprecationMessage)
+	column.RemovedIn, _ = f.Metadata.GetValue(MetadataRemovedIn)
+
+	v, ok = f.Metadata.GetValue(MetadataPartitionKey)
+	column.PartitionKey = ok && v == MetadataTrue
+
+	v, _ = f.Metadata.GetValue(MetadataPartitionGranularity)
+	column.PartitionGranularity = PartitionGranularity(v)
+
+	if v, ok = f.Metadata.GetValue(MetadataPreviousNames); ok {
+		_ = json.Unmarshal([]byte(v), &column.PreviousNames)
+	}
+
 	return column
 }
 
@@ -102,6 +151,25 @@ func (c Column) ToArrowField() arrow.Field {
 	if c.PrimaryKeyComponent {
 		mdKV[MetadataPrimaryKeyComponent] = MetadataTrue
 	}
+	if c.Deprecated {
+		mdKV[MetadataDeprecated] = MetadataTrue
+	}
+	if c.DeprecationMessage != "" {
+		mdKV[MetadataDeprecationMessage] = c.DeprecationMessage
+	}
+	if c.RemovedIn != "" {
+		mdKV[MetadataRemovedIn] = c.RemovedIn
+	}
+	if c.PartitionKey {
+		mdKV[MetadataPartitionKey] = MetadataTrue
+	}
+	if c.PartitionGranularity != "" {
+		mdKV[MetadataPartitionGranularity] = string(c.PartitionGranularity)
+	}
+	if len(c.PreviousNames) > 0 {
+		asJSON, _ := json.Marshal(c.PreviousNames)
+		mdKV[MetadataPreviousNames] = string(asJSON)
+	}
 
 	return arrow.Field{
 		Name:     c.Name,
@@ -113,15 +181,21 @@ func (c Column) ToArrowField() arrow.Field {
 
 func (c Column) MarshalJSON() ([]byte, error) {
 	type Alias struct {
-		Name                string `json:"name"`
-		Type                string `json:"type"`
-		Description         string `json:"description"`
-		PrimaryKey          bool   `json:"primary_key"`
-		NotNull             bool   `json:"not_null"`
-		Unique              bool   `json:"unique"`
-		IncrementalKey      bool   `json:"incremental_key"`
-		PrimaryKeyComponent bool   `json:"primary_key_component"`
-		TypeSchema          string `json:"type_schema,omitempty"`
+		Name                 string   `json:"name"`
+		Type                 string   `json:"type"`
+		Description          string   `json:"description"`
+		PrimaryKey           bool     `json:"primary_key"`
+		NotNull              bool     `json:"not_null"`
+		Unique               bool     `json:"unique"`
+		IncrementalKey       bool     `json:"incremental_key"`
+		PrimaryKeyComponent  bool     `json:"primary_key_component"`
+		TypeSchema           string   `json:"type_schema,omitempty"`
+		Deprecated           bool     `json:"deprecated,omitempty"`
+		DeprecationMessage   string   `json:"deprecation_message,omitempty"`
+		RemovedIn            string   `json:"removed_in,omitempty"`
+		PartitionKey         bool     `json:"partition_key,omitempty"`
+		PartitionGranularity string   `json:"partition_granularity,omitempty"`
+		PreviousNames        []string `json:"previous_names,omitempty"`
 	}
 	var alias Alias
 	alias.Name = c.Name
@@ -133,6 +207,12 @@ func (c Column) MarshalJSON() ([]byte, error) {
 	alias.IncrementalKey = c.IncrementalKey
 	alias.PrimaryKeyComponent = c.PrimaryKeyComponent
 	alias.TypeSchema = c.TypeSchema
+	alias.Deprecated = c.Deprecated
+	alias.DeprecationMessage = c.DeprecationMessage
+	alias.RemovedIn = c.RemovedIn
+	alias.PartitionKey = c.PartitionKey
+	alias.PartitionGranularity = string(c.PartitionGranularity)
+	alias.PreviousNames = c.PreviousNames
 
 	return json.Marshal(alias)
 }
@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/scalar"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+)
+
+// ColumnValidator validates a non-null value of the column, returning an error describing the violation.
+// Validators are run by Resource.Validate, in order, once the column resolvers ran. They must not include the value
+// in the error, as it may be sensitive.
+type ColumnValidator func(c Column, value scalar.Scalar) error
+
+// ColumnValidationError is returned by Resource.Validate for every column value rejected by a validator.
+type ColumnValidationError struct {
+	Column string
+	Err    error
+}
+
+func (e *ColumnValidationError) Error() string {
+	return fmt.Sprintf("invalid value of column %s: %s", e.Column, e.Err)
+}
+
+func (e *ColumnValidationError) Unwrap() error {
+	return e.Err
+}
+
+// RegexValidator rejects values whose string representation doesn't match the pattern.
+// It panics if the pattern can't be compiled.
+func RegexValidator(pattern string) ColumnValidator {
+	re := regexp.MustCompile(pattern)
+	return func(_ Column, value scalar.Scalar) error {
+		if !re.MatchString(value.String()) {
+			return fmt.Errorf("value doesn't match pattern %q", pattern)
+		}
+		return nil
+	}
+}
+
+// RangeValidator rejects numeric values outside of [minValue, maxValue], and values that aren't numeric.
+func RangeValidator(minValue, maxValue float64) ColumnValidator {
+	return func(_ Column, value scalar.Scalar) error {
+		var v float64
+		switch s := value.(type) {
+		case *scalar.Int:
+			v = float64(s.Value)
+		case *scalar.Uint:
+			v = float64(s.Value)
+		case *scalar.Float:
+			v = s.Value
+		default:
+			return fmt.Errorf("value of type %s is not numeric", value.DataType())
+		}
+		if v < minValue || v > maxValue {
+			return fmt.Errorf("value out of range [%v, %v]", minValue, maxValue)
+		}
+		return nil
+	}
+}
+
+// EnumValidator rejects values whose string representation isn't one of the given values.
+func EnumValidator(values ...string) ColumnValidator {
+	return func(_ Column, value scalar.Scalar) error {
+		if !slices.Contains(values, value.String()) {
+			return fmt.Errorf("value is not one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+// TypeSchemaValidator rejects JSON values that don't match the column's TypeSchema: object fields, list elements
+// and map values must have a JSON type compatible with their Arrow type. Fields missing from the TypeSchema and null
+// values are accepted. It accepts all values of columns without a TypeSchema.
+func TypeSchemaValidator() ColumnValidator {
+	var typeSchemas sync.Map // k = type schema, v = any (the decoded type schema)
+	return func(c Column, value scalar.Scalar) error {
+		if c.TypeSchema == "" {
+			return nil
+		}
+		if !arrow.TypeEqual(value.DataType(), types.ExtensionTypes.JSON) {
+			return fmt.Errorf("value of type %s is not JSON", value.DataType())
+		}
+		typeSchema, ok := typeSchemas.Load(c.TypeSchema)
+		if !ok {
+			if err := json.Unmarshal([]byte(c.TypeSchema), &typeSchema); err != nil {
+				return fmt.Errorf("invalid type schema: %w", err)
+			}
+			typeSchemas.Store(c.TypeSchema, typeSchema)
+		}
+		var v any
+		if err := json.Unmarshal(value.(*scalar.JSON).Value, &v); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		return matchTypeSchema("$", typeSchema, v)
+	}
+}
+
+// matchTypeSchema checks the decoded JSON value v against the type schema generated by transformers.
+func matchTypeSchema(path string, typeSchema any, v any) error {
+	if v == nil {
+		return nil
+	}
+	switch ts := typeSchema.(type) {
+	case string:
+		return matchTypeName(path, ts, v)
+	case []any:
+		elems, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a list", path)
+		}
+		if len(ts) == 0 {
+			return nil
+		}
+		for i, elem := range elems {
+			if err := matchTypeSchema(fmt.Sprintf("%s[%d]", path, i), ts[0], elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object", path)
+		}
+		// maps are described by a single field named after the key type
+		if len(ts) == 1 {
+			for keyType, valueSchema := range ts {
+				if _, ok := obj[keyType]; !ok && isTypeName(keyType) {
+					for key, value := range obj {
+						if err := matchTypeSchema(path+"."+key, valueSchema, value); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+			}
+		}
+		for name, fieldSchema := range ts {
+			if err := matchTypeSchema(path+"."+name, fieldSchema, obj[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func isTypeName(name string) bool {
+	return name == "utf8" || name == "large_utf8" || strings.HasPrefix(name, "int") || strings.HasPrefix(name, "uint")
+}
+
+func matchTypeName(path string, typeName string, v any) error {
+	var ok bool
+	switch {
+	case typeName == "any" || typeName == "json" || typeName == "extension<cq.json>":
+		return nil
+	case typeName == "bool":
+		_, ok = v.(bool)
+	case strings.HasPrefix(typeName, "int"), strings.HasPrefix(typeName, "uint"), strings.HasPrefix(typeName, "float"):
+		_, ok = v.(float64)
+	default:
+		// strings, timestamps, UUIDs and other types encoded as strings
+		_, ok = v.(string)
+	}
+	if !ok {
+		return errors.New(path + ": expected " + typeName)
+	}
+	return nil
+}
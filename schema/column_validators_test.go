@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/scalar"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnValidators(t *testing.T) {
+	typeSchema := `{"name":"utf8","count":"int64","tags":{"utf8":"utf8"},"items":[{"enabled":"bool"}],"extra":"any"}`
+	tests := []struct {
+		name      string
+		column    Column
+		validator ColumnValidator
+		value     any
+		valid     bool
+	}{
+		{name: "regex match", validator: RegexValidator(`^i-[0-9a-f]+$`), value: "i-0abc", valid: true},
+		{name: "regex mismatch", validator: RegexValidator(`^i-[0-9a-f]+$`), value: "vol-0abc", valid: false},
+		{name: "range int", validator: RangeValidator(1, 65535), value: int64(443), valid: true},
+		{name: "range below", validator: RangeValidator(1, 65535), value: int64(0), valid: false},
+		{name: "range float above", validator: RangeValidator(0, 1), value: 1.5, valid: false},
+		{name: "range not numeric", validator: RangeValidator(0, 1), value: "1", valid: false},
+		{name: "enum member", validator: EnumValidator("running", "stopped"), value: "running", valid: true},
+		{name: "enum not member", validator: EnumValidator("running", "stopped"), value: "pending", valid: false},
+		{
+			name:      "type schema match",
+			column:    Column{TypeSchema: typeSchema},
+			validator: TypeSchemaValidator(),
+			value:     `{"name":"a","count":1,"tags":{"k":"v"},"items":[{"enabled":true},{"enabled":null}],"extra":[1],"unknown":1}`,
+			valid:     true,
+		},
+		{
+			name:      "type schema field mismatch",
+			column:    Column{TypeSchema: typeSchema},
+			validator: TypeSchemaValidator(),
+			value:     `{"name":1}`,
+			valid:     false,
+		},
+		{
+			name:      "type schema map value mismatch",
+			column:    Column{TypeSchema: typeSchema},
+			validator: TypeSchemaValidator(),
+			value:     `{"tags":{"k":1}}`,
+			valid:     false,
+		},
+		{
+			name:      "type schema list element mismatch",
+			column:    Column{TypeSchema: typeSchema},
+			validator: TypeSchemaValidator(),
+			value:     `{"items":[{"enabled":"yes"}]}`,
+			valid:     false,
+		},
+		{
+			name:      "without type schema",
+			validator: TypeSchemaValidator(),
+			value:     `{"name":1}`,
+			valid:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dt arrow.DataType
+			switch tt.value.(type) {
+			case int64:
+				dt = arrow.PrimitiveTypes.Int64
+			case float64:
+				dt = arrow.PrimitiveTypes.Float64
+			default:
+				dt = arrow.BinaryTypes.String
+				if tt.column.TypeSchema != "" || tt.name == "without type schema" {
+					dt = types.ExtensionTypes.JSON
+				}
+			}
+			s := scalar.NewScalar(dt)
+			require.NoError(t, s.Set(tt.value))
+			err := tt.validator(tt.column, s)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
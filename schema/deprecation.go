@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DeprecationNotice returns a short description of the deprecation of the table, or an empty string if the table
+// isn't deprecated.
+func (t *Table) DeprecationNotice() string {
+	return deprecationNotice(t.Deprecated, t.DeprecationMessage, t.RemovedIn)
+}
+
+// DeprecationNotice returns a short description of the deprecation of the column, or an empty string if the column
+// isn't deprecated.
+func (c Column) DeprecationNotice() string {
+	return deprecationNotice(c.Deprecated, c.DeprecationMessage, c.RemovedIn)
+}
+
+func deprecationNotice(deprecated bool, message, removedIn string) string {
+	if !deprecated {
+		return ""
+	}
+	notice := "deprecated"
+	if removedIn != "" {
+		notice += " and will be removed in " + removedIn
+	}
+	if message != "" {
+		notice += ": " + message
+	}
+	return notice
+}
+
+// ValidateRemovals returns an error if a table or column was due to be removed in the given plugin version or an
+// earlier one, according to its RemovedIn field. Versions are compared as "vMAJOR.MINOR.PATCH", ignoring pre-release
+// and build suffixes. Nothing is validated if the plugin version can't be parsed, e.g. for development builds.
+func (tt Tables) ValidateRemovals(version string) error {
+	current, ok := parseVersion(version)
+	if !ok {
+		return nil
+	}
+	for _, t := range tt.FlattenTables() {
+		if removedIn, ok := parseVersion(t.RemovedIn); ok && slices.Compare(current[:], removedIn[:]) >= 0 {
+			return fmt.Errorf("table %s was due to be removed in %s", t.Name, t.RemovedIn)
+		}
+		for _, c := range t.Columns {
+			if removedIn, ok := parseVersion(c.RemovedIn); ok && slices.Compare(current[:], removedIn[:]) >= 0 {
+				return fmt.Errorf("column %s of table %s was due to be removed in %s", c.Name, t.Name, c.RemovedIn)
+			}
+		}
+	}
+	return nil
+}
+
+// parseVersion parses the major, minor and patch numbers of a version, ignoring pre-release and build suffixes.
+func parseVersion(v string) ([3]int, bool) {
+	var version [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > len(version) {
+		return version, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
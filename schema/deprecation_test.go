@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecationArrowMetadata(t *testing.T) {
+	table := &Table{
+		Name:               "test_table",
+		Deprecated:         true,
+		DeprecationMessage: "use test_table_v2 instead",
+		RemovedIn:          "v2.0.0",
+		Columns: ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "old", Type: arrow.BinaryTypes.String, Deprecated: true, DeprecationMessage: "use new instead", RemovedIn: "v1.5.0"},
+		},
+	}
+	got, err := NewTableFromArrowSchema(table.ToArrowSchema())
+	require.NoError(t, err)
+	require.True(t, got.Deprecated)
+	require.Equal(t, table.DeprecationMessage, got.DeprecationMessage)
+	require.Equal(t, table.RemovedIn, got.RemovedIn)
+	require.False(t, got.Columns[0].Deprecated)
+	require.True(t, got.Columns[1].Deprecated)
+	require.Equal(t, "use new instead", got.Columns[1].DeprecationMessage)
+	require.Equal(t, "v1.5.0", got.Columns[1].RemovedIn)
+
+	require.Equal(t, "deprecated and will be removed in v2.0.0: use test_table_v2 instead", got.DeprecationNotice())
+	require.Empty(t, got.Columns[0].DeprecationNotice())
+}
+
+func TestTables_ValidateRemovals(t *testing.T) {
+	tables := Tables{
+		{
+			Name:       "test_table",
+			Deprecated: true,
+			RemovedIn:  "v2.0.0",
+			Columns:    ColumnList{{Name: "old", Type: arrow.BinaryTypes.String, Deprecated: true, RemovedIn: "v1.5.0"}},
+		},
+	}
+	tests := []struct {
+		version string
+		err     string
+	}{
+		{version: "v1.4.9"},
+		{version: "development"},
+		{version: "v1.5.0-rc1", err: "column old of table test_table was due to be removed in v1.5.0"},
+		{version: "v1.10.0", err: "column old of table test_table was due to be removed in v1.5.0"},
+		{version: "v2.0.0", err: "table test_table was due to be removed in v2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			err := tables.ValidateRemovals(tt.version)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -11,6 +11,7 @@ const (
 	ErrorPhasePreResourceResolver
 	ErrorPhaseColumnResolver
 	ErrorPhasePostResourceResolver
+	ErrorPhaseColumnValidation
 )
 
 func (p ErrorPhase) String() string {
@@ -25,6 +26,8 @@ func (p ErrorPhase) String() string {
 		return "column_resolver"
 	case ErrorPhasePostResourceResolver:
 		return "post_resource_resolver"
+	case ErrorPhaseColumnValidation:
+		return "column_validation"
 	default:
 		return "unknown"
 	}
@@ -35,14 +38,27 @@ type ErrorEvent struct {
 	Table  *Table
 	Client ClientMeta
 	Phase  ErrorPhase
-	// Column is set only when Phase == ErrorPhaseColumnResolver.
+	// Column is set only when Phase is ErrorPhaseColumnResolver or ErrorPhaseColumnValidation.
 	Column *Column
+	// OnThrottled is set by the scheduler to slow down the table client, see ReportThrottled.
+	OnThrottled func()
+}
+
+// ReportThrottled reports that the error was caused by the API throttling the client. The scheduler then slows down
+// the calls to the resolvers of the table for this client with an adaptive backoff. It's meant to be called by an
+// ErrorClassifier, and is a no-op if OnThrottled isn't set.
+func (e ErrorEvent) ReportThrottled() {
+	if e.OnThrottled != nil {
+		e.OnThrottled()
+	}
 }
 
 // ErrorClassifier reports whether a resolver error should be suppressed rather than
 // raised. Suppressed errors are logged at debug level and are not counted in error
 // metrics or emitted as a SyncError message. A nil ErrorClassifier raises every error.
-// It is not consulted for primary key calculation or validation errors.
+// Throttling errors are reported with ErrorEvent.ReportThrottled, whether they're suppressed or not.
+// It is not consulted for primary key calculation or validation errors, but is for column validation errors.
+// Resources with column validation errors that are not suppressed are not sent.
 // The classifier may be invoked concurrently; implementations must be safe for concurrent use.
 type ErrorClassifier func(ctx context.Context, err error, event ErrorEvent) bool
 
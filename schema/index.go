@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Index declares a secondary index that destinations supporting this should create on the table.
+type Index struct {
+	// Name of the index. It must be unique within the table.
+	Name string `json:"name"`
+	// Columns are the names of the indexed columns, in order.
+	Columns []string `json:"columns"`
+	// Unique requires the destinations supporting this to reject rows with the same values for the indexed columns.
+	Unique bool `json:"unique,omitempty"`
+}
+
+func (i Index) String() string {
+	var sb strings.Builder
+	sb.WriteString(i.Name)
+	sb.WriteString(":(")
+	sb.WriteString(strings.Join(i.Columns, ","))
+	sb.WriteString(")")
+	if i.Unique {
+		sb.WriteString(":Unique")
+	}
+	return sb.String()
+}
+
+// Equal reports whether both indexes have the same name, columns and uniqueness.
+func (i Index) Equal(other Index) bool {
+	return i.Name == other.Name && i.Unique == other.Unique && slices.Equal(i.Columns, other.Columns)
+}
+
+// GetIndex returns the index with the given name, or nil if the table has no such index.
+func (t *Table) GetIndex(name string) *Index {
+	for i := range t.Indexes {
+		if t.Indexes[i].Name == name {
+			return &t.Indexes[i]
+		}
+	}
+	return nil
+}
+
+// ValidateIndexes checks the index declarations of all the tables.
+func (tt Tables) ValidateIndexes() error {
+	for _, t := range tt.FlattenTables() {
+		if err := t.ValidateIndexes(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateIndexes checks that indexes have distinct names and index distinct columns of the table.
+func (t *Table) ValidateIndexes() error {
+	for i, index := range t.Indexes {
+		if index.Name == "" {
+			return fmt.Errorf("index %d of table %s has no name", i, t.Name)
+		}
+		if slices.ContainsFunc(t.Indexes[:i], func(other Index) bool { return other.Name == index.Name }) {
+			return fmt.Errorf("index %s of table %s is duplicated", index.Name, t.Name)
+		}
+		if len(index.Columns) == 0 {
+			return fmt.Errorf("index %s of table %s has no columns", index.Name, t.Name)
+		}
+		for j, name := range index.Columns {
+			if t.Columns.Get(name) == nil {
+				return fmt.Errorf("index %s of table %s has unknown column %s", index.Name, t.Name, name)
+			}
+			if slices.Contains(index.Columns[:j], name) {
+				return fmt.Errorf("index %s of table %s has duplicated column %s", index.Name, t.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// getIndexChanges returns the index changes between the tables when t is the new one and old is the old one.
+func (t *Table) getIndexChanges(old *Table) []TableColumnChange {
+	var changes []TableColumnChange
+	for _, index := range old.Indexes {
+		if current := t.GetIndex(index.Name); current == nil || !current.Equal(index) {
+			changes = append(changes, TableColumnChange{
+				Type:          TableColumnChangeTypeRemoveIndex,
+				PreviousIndex: index,
+			})
+		}
+	}
+	for _, index := range t.Indexes {
+		if previous := old.GetIndex(index.Name); previous == nil || !previous.Equal(index) {
+			changes = append(changes, TableColumnChange{
+				Type:         TableColumnChangeTypeAddIndex,
+				CurrentIndex: index,
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_ValidateIndexes(t *testing.T) {
+	columns := ColumnList{
+		{Name: "arn", Type: arrow.BinaryTypes.String},
+		{Name: "region", Type: arrow.BinaryTypes.String},
+	}
+	tests := []struct {
+		name    string
+		indexes []Index
+		err     string
+	}{
+		{
+			name:    "valid",
+			indexes: []Index{{Name: "arn_idx", Columns: []string{"arn"}, Unique: true}, {Name: "region_arn_idx", Columns: []string{"region", "arn"}}},
+		},
+		{
+			name:    "no name",
+			indexes: []Index{{Columns: []string{"arn"}}},
+			err:     "index 0 of table test has no name",
+		},
+		{
+			name:    "duplicate name",
+			indexes: []Index{{Name: "idx", Columns: []string{"arn"}}, {Name: "idx", Columns: []string{"region"}}},
+			err:     "index idx of table test is duplicated",
+		},
+		{
+			name:    "no columns",
+			indexes: []Index{{Name: "idx"}},
+			err:     "index idx of table test has no columns",
+		},
+		{
+			name:    "unknown column",
+			indexes: []Index{{Name: "idx", Columns: []string{"name"}}},
+			err:     "index idx of table test has unknown column name",
+		},
+		{
+			name:    "duplicate column",
+			indexes: []Index{{Name: "idx", Columns: []string{"arn", "arn"}}},
+			err:     "index idx of table test has duplicated column arn",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Tables{{Name: "test", Columns: columns, Indexes: tt.indexes}}.ValidateIndexes()
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestTable_GetChangesIndexes(t *testing.T) {
+	columns := ColumnList{
+		{Name: "arn", Type: arrow.BinaryTypes.String},
+		{Name: "region", Type: arrow.BinaryTypes.String},
+	}
+	arnIndex := Index{Name: "arn_idx", Columns: []string{"arn"}}
+	regionIndex := Index{Name: "region_idx", Columns: []string{"region"}}
+	uniqueRegionIndex := Index{Name: "region_idx", Columns: []string{"region"}, Unique: true}
+
+	source := &Table{Name: "test", Columns: columns, Indexes: []Index{arnIndex, regionIndex}}
+	target := &Table{Name: "test", Columns: columns, Indexes: []Index{uniqueRegionIndex}}
+	require.Equal(t, []TableColumnChange{
+		{Type: TableColumnChangeTypeRemoveIndex, PreviousIndex: arnIndex},
+		{Type: TableColumnChangeTypeRemoveIndex, PreviousIndex: regionIndex},
+		{Type: TableColumnChangeTypeAddIndex, CurrentIndex: uniqueRegionIndex},
+	}, target.GetChanges(source, WithIndexChanges()))
+	require.Nil(t, source.GetChanges(source, WithIndexChanges()))
+	// destinations not supporting indexes don't get index changes
+	require.Nil(t, target.GetChanges(source))
+
+	summary := GetChangesSummary(map[string][]TableColumnChange{"test": target.GetChanges(source, WithIndexChanges())})
+	require.Equal(t, `test:
+  - Index "arn_idx" removed
+  - Index "region_idx" removed
+  - Unique index "region_idx" added on columns ["region"]`, summary)
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// PartitionGranularity is the time unit temporal partition key columns are truncated to, to partition a table.
+type PartitionGranularity string
+
+const (
+	PartitionGranularityHour  PartitionGranularity = "hour"
+	PartitionGranularityDay   PartitionGranularity = "day"
+	PartitionGranularityMonth PartitionGranularity = "month"
+	PartitionGranularityYear  PartitionGranularity = "year"
+)
+
+var partitionGranularities = []PartitionGranularity{
+	PartitionGranularityHour,
+	PartitionGranularityDay,
+	PartitionGranularityMonth,
+	PartitionGranularityYear,
+}
+
+// PartitionKeys returns the names of the partition key columns, in the order of the columns.
+func (t *Table) PartitionKeys() []string {
+	var partitionKeys []string
+	for _, c := range t.Columns {
+		if c.PartitionKey {
+			partitionKeys = append(partitionKeys, c.Name)
+		}
+	}
+
+	return partitionKeys
+}
+
+// ValidatePartitioning checks that the partitioning and sort key hints of all the tables refer to valid columns.
+func (tt Tables) ValidatePartitioning() error {
+	for _, t := range tt.FlattenTables() {
+		if err := t.ValidatePartitioning(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatePartitioning checks that partition granularities are only set on temporal partition key columns, and that
+// sort keys are distinct columns of the table.
+func (t *Table) ValidatePartitioning() error {
+	for _, c := range t.Columns {
+		if c.PartitionGranularity == "" {
+			continue
+		}
+		if !c.PartitionKey {
+			return fmt.Errorf("column %s of table %s has a partition granularity but isn't a partition key", c.Name, t.Name)
+		}
+		if !slices.Contains(partitionGranularities, c.PartitionGranularity) {
+			return fmt.Errorf("column %s of table %s has an unknown partition granularity %q", c.Name, t.Name, c.PartitionGranularity)
+		}
+		switch c.Type.ID() {
+		case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		default:
+			return fmt.Errorf("column %s of table %s has a partition granularity but type %s isn't temporal", c.Name, t.Name, c.Type)
+		}
+	}
+	for i, name := range t.SortKeys {
+		if t.Columns.Get(name) == nil {
+			return fmt.Errorf("sort key %s of table %s is not a column", name, t.Name)
+		}
+		if slices.Contains(t.SortKeys[:i], name) {
+			return fmt.Errorf("sort key %s of table %s is duplicated", name, t.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_ValidatePartitioning(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *Table
+		err   string
+	}{
+		{
+			name: "valid",
+			table: &Table{
+				Name: "test",
+				Columns: ColumnList{
+					{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us, PartitionKey: true, PartitionGranularity: PartitionGranularityMonth},
+					{Name: "region", Type: arrow.BinaryTypes.String, PartitionKey: true},
+					{Name: "id", Type: arrow.BinaryTypes.String},
+				},
+				SortKeys: []string{"region", "id"},
+			},
+		},
+		{
+			name: "granularity without partition key",
+			table: &Table{
+				Name:    "test",
+				Columns: ColumnList{{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us, PartitionGranularity: PartitionGranularityDay}},
+			},
+			err: "column created_at of table test has a partition granularity but isn't a partition key",
+		},
+		{
+			name: "unknown granularity",
+			table: &Table{
+				Name:    "test",
+				Columns: ColumnList{{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us, PartitionKey: true, PartitionGranularity: "week"}},
+			},
+			err: `column created_at of table test has an unknown partition granularity "week"`,
+		},
+		{
+			name: "granularity on non-temporal column",
+			table: &Table{
+				Name:    "test",
+				Columns: ColumnList{{Name: "region", Type: arrow.BinaryTypes.String, PartitionKey: true, PartitionGranularity: PartitionGranularityDay}},
+			},
+			err: "column region of table test has a partition granularity but type utf8 isn't temporal",
+		},
+		{
+			name:  "unknown sort key",
+			table: &Table{Name: "test", Columns: ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}}, SortKeys: []string{"name"}},
+			err:   "sort key name of table test is not a column",
+		},
+		{
+			name:  "duplicate sort key",
+			table: &Table{Name: "test", Columns: ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}}, SortKeys: []string{"id", "id"}},
+			err:   "sort key id of table test is duplicated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Tables{tt.table}.ValidatePartitioning()
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestTable_PartitionKeys(t *testing.T) {
+	table := &Table{
+		Name: "test",
+		Columns: ColumnList{
+			{Name: "id", Type: arrow.BinaryTypes.String},
+			{Name: "region", Type: arrow.BinaryTypes.String, PartitionKey: true},
+			{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us, PartitionKey: true},
+		},
+	}
+	require.Equal(t, []string{"region", "created_at"}, table.PartitionKeys())
+}
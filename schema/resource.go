@@ -2,6 +2,7 @@ package schema
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"hash"
 	"slices"
@@ -147,7 +148,9 @@ func (e *PKComponentError) Error() string {
 	return fmt.Sprintf("missing primary key component on columns: %v", e.MissingPKComponents)
 }
 
-// Validates that all primary keys have values.
+// Validate checks that all primary keys have values, then runs the column validators.
+// A *PKError is returned alone. Otherwise, the *PKComponentError and the *ColumnValidationError of every rejected
+// column value are joined.
 func (r *Resource) Validate() error {
 	var missingPks []string
 	var missingPKComponents []string
@@ -165,10 +168,25 @@ func (r *Resource) Validate() error {
 	if len(missingPks) > 0 {
 		return &PKError{MissingPKs: missingPks}
 	}
+	var errs []error
 	if len(missingPKComponents) > 0 {
-		return &PKComponentError{MissingPKComponents: missingPKComponents}
+		errs = append(errs, &PKComponentError{MissingPKComponents: missingPKComponents})
 	}
-	return nil
+	for i, c := range r.Table.Columns {
+		if len(c.Validators) == 0 || !r.data[i].IsValid() {
+			continue
+		}
+		for _, validator := range c.Validators {
+			if err := validator(c, r.data[i]); err != nil {
+				errs = append(errs, &ColumnValidationError{Column: c.Name, Err: err})
+				break
+			}
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
 }
 
 func (rr Resources) TableName() string {
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/apache/arrow-go/v18/arrow"
@@ -60,6 +61,36 @@ func TestResource_Validate(t *testing.T) {
 				return resource.Set("col1", "test")
 			},
 		},
+		{
+			name:     "valid resource with column validators",
+			resource: NewResourceData(&Table{Name: "test", Columns: ColumnList{{Name: "col1", Type: arrow.BinaryTypes.String, Validators: []ColumnValidator{EnumValidator("a", "b")}}}}, nil, nil),
+			err:      nil,
+			valueSetter: func(resource *Resource) error {
+				return resource.Set("col1", "a")
+			},
+		},
+		{
+			name:     "invalid resource with column validators",
+			resource: NewResourceData(&Table{Name: "test", Columns: ColumnList{{Name: "col1", Type: arrow.BinaryTypes.String, Validators: []ColumnValidator{EnumValidator("a", "b")}}}}, nil, nil),
+			err:      &ColumnValidationError{Column: "col1", Err: errors.New("value is not one of a, b")},
+			valueSetter: func(resource *Resource) error {
+				return resource.Set("col1", "c")
+			},
+		},
+		{
+			name: "invalid resource with primary key components and column validators",
+			resource: NewResourceData(&Table{Name: "test", Columns: ColumnList{
+				{Name: "col1", Type: arrow.BinaryTypes.String, PrimaryKeyComponent: true},
+				{Name: "col2", Type: arrow.PrimitiveTypes.Int64, Validators: []ColumnValidator{RangeValidator(0, 10)}},
+			}}, nil, nil),
+			err: errors.Join(
+				&PKComponentError{MissingPKComponents: []string{"col1"}},
+				&ColumnValidationError{Column: "col2", Err: errors.New("value out of range [0, 10]")},
+			),
+			valueSetter: func(resource *Resource) error {
+				return resource.Set("col2", 11)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
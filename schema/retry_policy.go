@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how the scheduler retries failed resolver calls. The zero value doesn't retry.
+// Panics are never retried, and errors are only passed to the ErrorClassifier once all the attempts failed.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls of a resolver, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles for every following retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries. There's no cap if it's 0.
+	MaxBackoff time.Duration
+	// Jitter randomizes every delay by up to the given fraction of it, between 0 and 1.
+	Jitter float64
+	// Retryable reports whether the error should be retried. All errors are retried if it's nil.
+	Retryable func(err error) bool
+}
+
+// Backoff returns the delay before the given retry, starting at 1.
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 && d > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * jitter * float64(d))
+	}
+	return d
+}
+
+// Do calls fn until it succeeds, returns an error that isn't retryable, ctx is done or MaxAttempts calls were made,
+// and returns the number of retries along with the error of the last call.
+// onRetry, if not nil, is called with the error of the previous call before every retry.
+// A nil RetryPolicy calls fn once.
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error, onRetry func(retry int, err error)) (int, error) {
+	err := fn()
+	if p == nil {
+		return 0, err
+	}
+	retries := 0
+	for ; err != nil && retries+1 < p.MaxAttempts; retries++ {
+		if p.Retryable != nil && !p.Retryable(err) {
+			break
+		}
+		if onRetry != nil {
+			onRetry(retries+1, err)
+		}
+		t := time.NewTimer(p.Backoff(retries + 1))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return retries, err
+		}
+		err = fn()
+	}
+	return retries, err
+}
@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 35 * time.Millisecond}
+	require.Equal(t, 10*time.Millisecond, p.Backoff(1))
+	require.Equal(t, 20*time.Millisecond, p.Backoff(2))
+	require.Equal(t, 35*time.Millisecond, p.Backoff(3))
+	require.Equal(t, 35*time.Millisecond, p.Backoff(100))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		require.InDelta(t, float64(20*time.Millisecond), float64(p.Backoff(2)), float64(10*time.Millisecond))
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		policy      *RetryPolicy
+		errs        []error
+		wantCalls   int
+		wantRetries int
+		wantErr     error
+	}{
+		{
+			name:      "nil policy calls once",
+			errs:      []error{errTransient, nil},
+			wantCalls: 1,
+			wantErr:   errTransient,
+		},
+		{
+			name:        "retries until success",
+			policy:      &RetryPolicy{MaxAttempts: 3},
+			errs:        []error{errTransient, errTransient, nil},
+			wantCalls:   3,
+			wantRetries: 2,
+		},
+		{
+			name:        "stops after max attempts",
+			policy:      &RetryPolicy{MaxAttempts: 2},
+			errs:        []error{errTransient, errTransient, nil},
+			wantCalls:   2,
+			wantRetries: 1,
+			wantErr:     errTransient,
+		},
+		{
+			name: "stops on errors that aren't retryable",
+			policy: &RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool {
+				return errors.Is(err, errTransient)
+			}},
+			errs:        []error{errTransient, errPermanent, nil},
+			wantCalls:   2,
+			wantRetries: 1,
+			wantErr:     errPermanent,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, onRetryCalls := 0, 0
+			retries, err := tc.policy.Do(ctx, func() error {
+				calls++
+				return tc.errs[calls-1]
+			}, func(int, error) {
+				onRetryCalls++
+			})
+			require.Equal(t, tc.wantCalls, calls)
+			require.Equal(t, tc.wantRetries, retries)
+			require.Equal(t, tc.wantRetries, onRetryCalls)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		calls := 0
+		p := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute}
+		_, err := p.Do(cancelled, func() error {
+			calls++
+			return errTransient
+		}, nil)
+		require.Equal(t, 1, calls)
+		require.ErrorIs(t, err, errTransient)
+	})
+}
@@ -26,6 +26,8 @@ type TableResolver func(ctx context.Context, meta ClientMeta, parent *Resource,
 type RowResolver func(ctx context.Context, meta ClientMeta, resource *Resource) error
 
 // EXPERIMENTAL: RowsChunkResolver API might change in future versions of the SDK
+//
+// RowsChunkResolver is called with chunks of up to ChunkSize rows at once.
 type RowsChunkResolver struct {
 	ChunkSize    int
 	RowsResolver func(ctx context.Context, meta ClientMeta, resourcesChunk []*Resource) error
@@ -56,6 +58,17 @@ const (
 	TableColumnChangeTypeRemoveUniqueConstraint
 	// Moving from composite pks to singular PK on _cq_id this will give destination plugins the ability to auto migrate
 	TableColumnChangeTypeMoveToCQOnly
+	// A column was renamed from one of its Column.PreviousNames: ColumnName is the new name, and Previous holds the
+	// column under its old name. Destinations can rename the column instead of dropping its data.
+	// Only reported by GetChanges with WithColumnRenames.
+	TableColumnChangeTypeRename
+	// The type of a column was widened without other changes (see IsSafeTypeWidening), so that destinations can
+	// migrate the column while keeping its data. Only reported by GetChanges with WithTypeWidening.
+	TableColumnChangeTypeWidenType
+	// An index was added to the table: CurrentIndex holds it. Only reported by GetChanges with WithIndexChanges.
+	TableColumnChangeTypeAddIndex
+	// An index was removed from the table: PreviousIndex holds it. Only reported by GetChanges with WithIndexChanges.
+	TableColumnChangeTypeRemoveIndex
 )
 
 type TableColumnChange struct {
@@ -63,6 +76,9 @@ type TableColumnChange struct {
 	ColumnName string
 	Current    Column
 	Previous   Column
+	// CurrentIndex and PreviousIndex are only set for index changes.
+	CurrentIndex  Index
+	PreviousIndex Index
 }
 
 type Table struct {
@@ -87,14 +103,17 @@ type Table struct {
 	// Multiplex returns re-purposed meta clients. The sdk will execute the table with each of them
 	Multiplex Multiplexer `json:"-"`
 	// PostResourceResolver is called after all columns have been resolved, but before the Resource is sent to be inserted. The ordering of resolvers is:
-	//  (Table) Resolver → PreResourceResolver → ColumnResolvers → PostResourceResolver
+	//  (Table) Resolver → PreResourceChunkResolver → PreResourceResolver → ColumnResolvers → Column ChunkResolvers → PostResourceResolver → PostResourceChunkResolver
 	PostResourceResolver RowResolver `json:"-"`
 	// PreResourceResolver is called before all columns are resolved but after Resource is created. The ordering of resolvers is:
-	//  (Table) Resolver → PreResourceResolver → ColumnResolvers → PostResourceResolver
+	//  (Table) Resolver → PreResourceChunkResolver → PreResourceResolver → ColumnResolvers → Column ChunkResolvers → PostResourceResolver → PostResourceChunkResolver
 	PreResourceResolver RowResolver `json:"-"`
 
 	PreResourceChunkResolver *RowsChunkResolver `json:"-"`
 
+	// PostResourceChunkResolver is called with chunks of resolved rows, after PostResourceResolver.
+	PostResourceChunkResolver *RowsChunkResolver `json:"-"`
+
 	// IsIncremental is a flag that indicates if the table is incremental or not. This flag mainly affects how the table is
 	// documented.
 	IsIncremental bool `json:"is_incremental"`
@@ -116,6 +135,34 @@ type Table struct {
 	// with whether the table makes use of a paid API or not.
 	IsPaid bool `json:"is_paid"`
 
+	// Priority is used by the priority scheduling strategy: top-level tables with a higher priority are resolved first,
+	// and tables with a positive priority P get P+1 times the per-table concurrency limits. Defaults to 0.
+	Priority int `json:"-"`
+
+	// RetryPolicy controls how the failed resolver calls of this table are retried, overriding the default retry
+	// policy of the scheduler. It doesn't apply to the relations of the table.
+	RetryPolicy *RetryPolicy `json:"-"`
+
+	// DependsOn lists the names of the top-level tables that must be synced for all clients before this top-level table
+	// is started. Their resources are available to the resolvers of this table through scheduler.DependencyResources.
+	// Dependencies that are not part of the sync are ignored. Only top-level tables may have dependencies.
+	DependsOn []string `json:"-"`
+
+	// Indexes declares the secondary indexes destinations supporting this should create on the table.
+	Indexes []Index `json:"indexes,omitempty"`
+
+	// SortKeys hints destinations supporting this to sort or cluster the table by these columns, in order.
+	SortKeys []string `json:"sort_keys,omitempty"`
+
+	// Deprecated marks the table as deprecated: it's still synced, but is going to be removed or renamed.
+	// A warning is logged when a deprecated table is explicitly selected for a sync.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// DeprecationMessage explains the deprecation, e.g. which table to use instead.
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+	// RemovedIn is the plugin version the table is going to be removed in, e.g. "v5.0.0".
+	// Plugin validation fails once the plugin version reaches it.
+	RemovedIn string `json:"removed_in,omitempty"`
+
 	// IgnorePKComponentsMismatchValidation is a flag that indicates if the table should skip validating usage of both primary key components and primary keys
 	IgnorePKComponentsMismatchValidation bool `json:"ignore_pk_components_mismatch_validation"`
 }
@@ -202,6 +249,10 @@ func NewTableFromArrowSchema(sc *arrow.Schema) (*Table, error) {
 	dependsOn, _ := tableMD.GetValue(MetadataTableDependsOn)
 	permissionsNeeded, _ := tableMD.GetValue(MetadataTablePermissionsNeeded)
 	sensitiveColumns, _ := tableMD.GetValue(MetadataTableSensitiveColumns)
+	deprecationMessage, _ := tableMD.GetValue(MetadataTableDeprecationMessage)
+	removedIn, _ := tableMD.GetValue(MetadataTableRemovedIn)
+	sortKeys, _ := tableMD.GetValue(MetadataTableSortKeys)
+	indexes, _ := tableMD.GetValue(MetadataTableIndexes)
 	var parent *Table
 	if dependsOn != "" {
 		parent = &Table{Name: dependsOn}
@@ -216,15 +267,27 @@ func NewTableFromArrowSchema(sc *arrow.Schema) (*Table, error) {
 	_ = json.Unmarshal([]byte(permissionsNeeded), &permissionsNeededArr)
 	var sensitiveColumnsArr []string
 	_ = json.Unmarshal([]byte(sensitiveColumns), &sensitiveColumnsArr)
+	var sortKeysArr []string
+	if sortKeys != "" {
+		_ = json.Unmarshal([]byte(sortKeys), &sortKeysArr)
+	}
+	var indexesArr []Index
+	if indexes != "" {
+		_ = json.Unmarshal([]byte(indexes), &indexesArr)
+	}
 	table := &Table{
-		Name:              name,
-		Description:       description,
-		PkConstraintName:  constraintName,
-		Columns:           columns,
-		Title:             title,
-		Parent:            parent,
-		PermissionsNeeded: permissionsNeededArr,
-		SensitiveColumns:  sensitiveColumnsArr,
+		Name:               name,
+		Description:        description,
+		PkConstraintName:   constraintName,
+		Columns:            columns,
+		Title:              title,
+		Parent:             parent,
+		PermissionsNeeded:  permissionsNeededArr,
+		SensitiveColumns:   sensitiveColumnsArr,
+		DeprecationMessage: deprecationMessage,
+		RemovedIn:          removedIn,
+		SortKeys:           sortKeysArr,
+		Indexes:            indexesArr,
 	}
 	if isIncremental, found := tableMD.GetValue(MetadataIncremental); found {
 		table.IsIncremental = isIncremental == MetadataTrue
@@ -232,6 +295,9 @@ func NewTableFromArrowSchema(sc *arrow.Schema) (*Table, error) {
 	if isPaid, found := tableMD.GetValue(MetadataTableIsPaid); found {
 		table.IsPaid = isPaid == MetadataTrue
 	}
+	if deprecated, found := tableMD.GetValue(MetadataTableDeprecated); found {
+		table.Deprecated = deprecated == MetadataTrue
+	}
 	return table, nil
 }
 
@@ -247,6 +313,14 @@ func (t TableColumnChangeType) String() string {
 		return "remove_unique_constraint"
 	case TableColumnChangeTypeMoveToCQOnly:
 		return "move_to_cq_only"
+	case TableColumnChangeTypeRename:
+		return "rename"
+	case TableColumnChangeTypeWidenType:
+		return "widen_type"
+	case TableColumnChangeTypeAddIndex:
+		return "add_index"
+	case TableColumnChangeTypeRemoveIndex:
+		return "remove_index"
 	default:
 		return "unknown"
 	}
@@ -264,6 +338,12 @@ func (t TableColumnChange) String() string {
 		return fmt.Sprintf("column: %s, previous: %s", t.ColumnName, t.Previous)
 	case TableColumnChangeTypeMoveToCQOnly:
 		return fmt.Sprintf("multi-column: %s, type: %s", t.ColumnName, t.Type)
+	case TableColumnChangeTypeRename, TableColumnChangeTypeWidenType:
+		return fmt.Sprintf("column: %s, type: %s, current: %s, previous: %s", t.ColumnName, t.Type, t.Current, t.Previous)
+	case TableColumnChangeTypeAddIndex:
+		return fmt.Sprintf("index: %s, type: %s", t.CurrentIndex, t.Type)
+	case TableColumnChangeTypeRemoveIndex:
+		return fmt.Sprintf("index: %s, type: %s", t.PreviousIndex, t.Type)
 	default:
 		return fmt.Sprintf("column: %s, type: %s, current: %s, previous: %s", t.ColumnName, t.Type, t.Current, t.Previous)
 	}
@@ -338,6 +418,17 @@ func GetColumnChangeSummary(change TableColumnChange) string {
 		return fmt.Sprintf("Unique constraint removed from column %q", change.ColumnName)
 	case TableColumnChangeTypeMoveToCQOnly:
 		return fmt.Sprintf("Primary key columns removed and replaced with a single column %q with type %q", change.ColumnName, change.Current.Type)
+	case TableColumnChangeTypeRename:
+		return fmt.Sprintf("Column %q renamed to %q", change.Previous.Name, change.ColumnName)
+	case TableColumnChangeTypeWidenType:
+		return fmt.Sprintf("Type widened from %q to %q for column %q", change.Previous.Type, change.Current.Type, change.ColumnName)
+	case TableColumnChangeTypeAddIndex:
+		if change.CurrentIndex.Unique {
+			return fmt.Sprintf("Unique index %q added on columns %q", change.CurrentIndex.Name, change.CurrentIndex.Columns)
+		}
+		return fmt.Sprintf("Index %q added on columns %q", change.CurrentIndex.Name, change.CurrentIndex.Columns)
+	case TableColumnChangeTypeRemoveIndex:
+		return fmt.Sprintf("Index %q removed", change.PreviousIndex.Name)
 	default:
 		return fmt.Sprintf("column: %s, type: %s, current: %s, previous: %s", change.ColumnName, change.Type, change.Current, change.Previous)
 	}
@@ -535,6 +626,58 @@ func (tt Tables) ValidateDuplicateTables() error {
 	return nil
 }
 
+// ValidateDependencies checks that only top-level tables have dependencies, that the dependencies are top-level
+// tables and that there are no dependency cycles.
+func (tt Tables) ValidateDependencies() error {
+	topLevel := make(map[string]*Table, len(tt))
+	for _, t := range tt {
+		topLevel[t.Name] = t
+	}
+	for _, t := range tt {
+		for _, rel := range t.Relations.FlattenTables() {
+			if len(rel.DependsOn) > 0 {
+				return fmt.Errorf("table %s is a relation and can't have dependencies", rel.Name)
+			}
+		}
+		for _, dep := range t.DependsOn {
+			if _, ok := topLevel[dep]; !ok {
+				return fmt.Errorf("table %s depends on %s which is not a top-level table", t.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(tt))
+	var visit func(t *Table, path []string) error
+	visit = func(t *Table, path []string) error {
+		path = append(path, t.Name)
+		switch state[t.Name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle %s", strings.Join(path[slices.Index(path, t.Name):], " -> "))
+		case visited:
+			return nil
+		}
+		state[t.Name] = visiting
+		for _, dep := range t.DependsOn {
+			if err := visit(topLevel[dep], path); err != nil {
+				return err
+			}
+		}
+		state[t.Name] = visited
+		return nil
+	}
+	for _, t := range tt {
+		if err := visit(t, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (tt Tables) GetPaidTables() Tables {
 	flattenedTables := tt.FlattenTables()
 	paidTables := make(Tables, 0, len(flattenedTables))
@@ -603,6 +746,23 @@ func (t *Table) ToArrowSchema() *arrow.Schema {
 	if t.IsPaid {
 		md[MetadataTableIsPaid] = MetadataTrue
 	}
+	if t.Deprecated {
+		md[MetadataTableDeprecated] = MetadataTrue
+	}
+	if t.DeprecationMessage != "" {
+		md[MetadataTableDeprecationMessage] = t.DeprecationMessage
+	}
+	if t.RemovedIn != "" {
+		md[MetadataTableRemovedIn] = t.RemovedIn
+	}
+	if len(t.SortKeys) > 0 {
+		asJSON, _ := json.Marshal(t.SortKeys)
+		md[MetadataTableSortKeys] = string(asJSON)
+	}
+	if len(t.Indexes) > 0 {
+		asJSON, _ := json.Marshal(t.Indexes)
+		md[MetadataTableIndexes] = string(asJSON)
+	}
 	asJSON, _ := json.Marshal(t.PermissionsNeeded)
 	md[MetadataTablePermissionsNeeded] = string(asJSON)
 	asJSON, _ = json.Marshal(t.SensitiveColumns)
@@ -616,8 +776,48 @@ func (t *Table) ToArrowSchema() *arrow.Schema {
 	return arrow.NewSchema(fields, &schemaMd)
 }
 
+// GetChangesOption enables change types that destinations have to support explicitly, see GetChanges.
+type GetChangesOption func(*getChangesOptions)
+
+type getChangesOptions struct {
+	renames      bool
+	typeWidening bool
+	indexes      bool
+}
+
+// WithColumnRenames makes GetChanges report a column renamed from one of its Column.PreviousNames as a
+// TableColumnChangeTypeRename, instead of the removal of the old column and the addition of the new one.
+func WithColumnRenames() GetChangesOption {
+	return func(o *getChangesOptions) {
+		o.renames = true
+	}
+}
+
+// WithTypeWidening makes GetChanges report safe type widenings (see IsSafeTypeWidening) as a
+// TableColumnChangeTypeWidenType, instead of a TableColumnChangeTypeUpdate.
+func WithTypeWidening() GetChangesOption {
+	return func(o *getChangesOptions) {
+		o.typeWidening = true
+	}
+}
+
+// WithIndexChanges makes GetChanges report the indexes that were added to or removed from Table.Indexes, as
+// TableColumnChangeTypeAddIndex and TableColumnChangeTypeRemoveIndex. Indexes that changed are removed and added
+// again.
+func WithIndexChanges() GetChangesOption {
+	return func(o *getChangesOptions) {
+		o.indexes = true
+	}
+}
+
 // GetChanges returns changes between two tables when t is the new one and old is the old one.
-func (t *Table) GetChanges(old *Table) []TableColumnChange {
+// Change types added after the initial ones are only reported with the options enabling them, so that destinations
+// that don't handle them keep getting the changes they know about.
+func (t *Table) GetChanges(old *Table, opts ...GetChangesOption) []TableColumnChange {
+	var o getChangesOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	var changes []TableColumnChange
 
 	//  Special case: Moving from individual pks to singular PK on _cq_id
@@ -627,22 +827,42 @@ func (t *Table) GetChanges(old *Table) []TableColumnChange {
 			Type: TableColumnChangeTypeMoveToCQOnly,
 		})
 	}
+	// old names of the renamed columns
+	renamed := make(map[string]bool)
 	for _, c := range t.Columns {
 		otherColumn := old.Columns.Get(c.Name)
-		// A column was added to the table definition
 		if otherColumn == nil {
+			if o.renames {
+				otherColumn = t.renamedColumn(c, old, renamed)
+			}
+			// A column was added to the table definition
+			if otherColumn == nil {
+				changes = append(changes, TableColumnChange{
+					Type:       TableColumnChangeTypeAdd,
+					ColumnName: c.Name,
+					Current:    c,
+				})
+				continue
+			}
+			// A column was renamed from one of its previous names
+			renamed[otherColumn.Name] = true
 			changes = append(changes, TableColumnChange{
-				Type:       TableColumnChangeTypeAdd,
+				Type:       TableColumnChangeTypeRename,
 				ColumnName: c.Name,
 				Current:    c,
+				Previous:   *otherColumn,
 			})
-			continue
 		}
 
 		// Column type or options (e.g. PK, Not Null) changed in the new table definition
-		if !arrow.TypeEqual(c.Type, otherColumn.Type) || c.NotNull != otherColumn.NotNull || c.PrimaryKey != otherColumn.PrimaryKey {
+		typeChanged := !arrow.TypeEqual(c.Type, otherColumn.Type)
+		if typeChanged || c.NotNull != otherColumn.NotNull || c.PrimaryKey != otherColumn.PrimaryKey {
+			changeType := TableColumnChangeTypeUpdate
+			if o.typeWidening && typeChanged && c.NotNull == otherColumn.NotNull && c.PrimaryKey == otherColumn.PrimaryKey && IsSafeTypeWidening(otherColumn.Type, c.Type) {
+				changeType = TableColumnChangeTypeWidenType
+			}
 			changes = append(changes, TableColumnChange{
-				Type:       TableColumnChangeTypeUpdate,
+				Type:       changeType,
 				ColumnName: c.Name,
 				Current:    c,
 				Previous:   *otherColumn,
@@ -660,7 +880,7 @@ func (t *Table) GetChanges(old *Table) []TableColumnChange {
 	}
 	// A column was removed from the table definition
 	for _, c := range old.Columns {
-		if t.Columns.Get(c.Name) == nil {
+		if t.Columns.Get(c.Name) == nil && !renamed[c.Name] {
 			changes = append(changes, TableColumnChange{
 				Type:       TableColumnChangeTypeRemove,
 				ColumnName: c.Name,
@@ -668,9 +888,26 @@ func (t *Table) GetChanges(old *Table) []TableColumnChange {
 			})
 		}
 	}
+	if o.indexes {
+		changes = append(changes, t.getIndexChanges(old)...)
+	}
 	return changes
 }
 
+// renamedColumn returns the column of the old table c was renamed from, or nil if c wasn't renamed.
+// Previous names that are still used by columns of t, or by columns already renamed, are ignored.
+func (t *Table) renamedColumn(c Column, old *Table, renamed map[string]bool) *Column {
+	for _, name := range c.PreviousNames {
+		if renamed[name] || t.Columns.Get(name) != nil {
+			continue
+		}
+		if previous := old.Columns.Get(name); previous != nil {
+			return previous
+		}
+	}
+	return nil
+}
+
 func (t *Table) ValidateDuplicateColumns() error {
 	columns := make(map[string]bool, len(t.Columns))
 	for _, c := range t.Columns {
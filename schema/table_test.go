@@ -494,6 +494,7 @@ type testTableGetChangeTestCase struct {
 	name            string
 	target          *Table
 	source          *Table
+	options         []GetChangesOption
 	expectedChanges []TableColumnChange
 }
 
@@ -735,12 +736,149 @@ var testTableGetChangeTestCases = []testTableGetChangeTestCase{
 			},
 		},
 	},
+	{
+		name:    "rename column",
+		options: []GetChangesOption{WithColumnRenames(), WithTypeWidening()},
+		target: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "new_name", Type: arrow.BinaryTypes.String, PreviousNames: []string{"old_name"}},
+			},
+		},
+		source: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "old_name", Type: arrow.BinaryTypes.String},
+			},
+		},
+		expectedChanges: []TableColumnChange{
+			{
+				Type:       TableColumnChangeTypeRename,
+				ColumnName: "new_name",
+				Current:    Column{Name: "new_name", Type: arrow.BinaryTypes.String, PreviousNames: []string{"old_name"}},
+				Previous:   Column{Name: "old_name", Type: arrow.BinaryTypes.String},
+			},
+		},
+	},
+	{
+		name:    "rename column with previous name still in use",
+		options: []GetChangesOption{WithColumnRenames(), WithTypeWidening()},
+		target: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "new_name", Type: arrow.BinaryTypes.String, PreviousNames: []string{"old_name"}},
+				{Name: "old_name", Type: arrow.BinaryTypes.String},
+			},
+		},
+		source: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "old_name", Type: arrow.BinaryTypes.String},
+			},
+		},
+		expectedChanges: []TableColumnChange{
+			{
+				Type:       TableColumnChangeTypeAdd,
+				ColumnName: "new_name",
+				Current:    Column{Name: "new_name", Type: arrow.BinaryTypes.String, PreviousNames: []string{"old_name"}},
+			},
+		},
+	},
+	{
+		name:    "rename and widen column",
+		options: []GetChangesOption{WithColumnRenames(), WithTypeWidening()},
+		target: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}},
+			},
+		},
+		source: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "total", Type: arrow.PrimitiveTypes.Int32},
+			},
+		},
+		expectedChanges: []TableColumnChange{
+			{
+				Type:       TableColumnChangeTypeRename,
+				ColumnName: "count",
+				Current:    Column{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}},
+				Previous:   Column{Name: "total", Type: arrow.PrimitiveTypes.Int32},
+			},
+			{
+				Type:       TableColumnChangeTypeWidenType,
+				ColumnName: "count",
+				Current:    Column{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}},
+				Previous:   Column{Name: "total", Type: arrow.PrimitiveTypes.Int32},
+			},
+		},
+	},
+	{
+		name:    "narrow column",
+		options: []GetChangesOption{WithColumnRenames(), WithTypeWidening()},
+		target: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "count", Type: arrow.PrimitiveTypes.Int32},
+			},
+		},
+		source: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+			},
+		},
+		expectedChanges: []TableColumnChange{
+			{
+				Type:       TableColumnChangeTypeUpdate,
+				ColumnName: "count",
+				Current:    Column{Name: "count", Type: arrow.PrimitiveTypes.Int32},
+				Previous:   Column{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+			},
+		},
+	},
+	{
+		name: "rename and widen column without options",
+		target: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}},
+				{Name: "value", Type: arrow.PrimitiveTypes.Int64},
+			},
+		},
+		source: &Table{
+			Name: "test",
+			Columns: []Column{
+				{Name: "total", Type: arrow.PrimitiveTypes.Int32},
+				{Name: "value", Type: arrow.PrimitiveTypes.Int32},
+			},
+		},
+		expectedChanges: []TableColumnChange{
+			{
+				Type:       TableColumnChangeTypeAdd,
+				ColumnName: "count",
+				Current:    Column{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}},
+			},
+			{
+				Type:       TableColumnChangeTypeUpdate,
+				ColumnName: "value",
+				Current:    Column{Name: "value", Type: arrow.PrimitiveTypes.Int64},
+				Previous:   Column{Name: "value", Type: arrow.PrimitiveTypes.Int32},
+			},
+			{
+				Type:       TableColumnChangeTypeRemove,
+				ColumnName: "total",
+				Previous:   Column{Name: "total", Type: arrow.PrimitiveTypes.Int32},
+			},
+		},
+	},
 }
 
 func TestTableGetChanges(t *testing.T) {
 	for _, tc := range testTableGetChangeTestCases {
 		t.Run(tc.name, func(t *testing.T) {
-			changes := tc.target.GetChanges(tc.source)
+			changes := tc.target.GetChanges(tc.source, tc.options...)
 			if diff := cmp.Diff(changes, tc.expectedChanges); diff != "" {
 				t.Errorf("diff (+got, -want): %v", diff)
 			}
@@ -775,9 +913,16 @@ func TestTablesToAndFromArrow(t *testing.T) {
 				{Name: "not_null", Type: arrow.BinaryTypes.String, NotNull: true},
 				{Name: "incremental_key", Type: arrow.BinaryTypes.String, IncrementalKey: true},
 				{Name: "multiple_attributes", Type: arrow.BinaryTypes.String, PrimaryKey: true, IncrementalKey: true, NotNull: true, Unique: true},
+				{Name: "renamed", Type: arrow.BinaryTypes.String, PreviousNames: []string{"old_name", "older_name"}},
+				{Name: "partition_key", Type: arrow.FixedWidthTypes.Date32, PartitionKey: true, PartitionGranularity: PartitionGranularityDay},
 			},
 			PermissionsNeeded: []string{"storage.buckets.list", "compute.acceleratorTypes.list", "test,test"},
 			SensitiveColumns:  []string{"string", "json"},
+			SortKeys:          []string{"string", "int"},
+			Indexes: []Index{
+				{Name: "test_table_string_idx", Columns: []string{"string"}},
+				{Name: "test_table_int_float_idx", Columns: []string{"int", "float"}, Unique: true},
+			},
 		},
 	}
 
@@ -793,6 +938,54 @@ func TestTablesToAndFromArrow(t *testing.T) {
 	}
 }
 
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables Tables
+		err    string
+	}{
+		{
+			name:   "should not return error for valid dependencies",
+			tables: Tables{{Name: "table1"}, {Name: "table2", DependsOn: []string{"table1"}}, {Name: "table3", DependsOn: []string{"table1", "table2"}}},
+		},
+		{
+			name:   "should return error when a dependency is unknown",
+			tables: Tables{{Name: "table1", DependsOn: []string{"table2"}}},
+			err:    "table table1 depends on table2 which is not a top-level table",
+		},
+		{
+			name:   "should return error when a relation has dependencies",
+			tables: Tables{{Name: "table1"}, {Name: "table2", Relations: Tables{{Name: "table3", DependsOn: []string{"table1"}}}}},
+			err:    "table table3 is a relation and can't have dependencies",
+		},
+		{
+			name:   "should return error when a table depends on itself",
+			tables: Tables{{Name: "table1", DependsOn: []string{"table1"}}},
+			err:    "dependency cycle table1 -> table1",
+		},
+		{
+			name: "should return error when dependencies have a cycle",
+			tables: Tables{
+				{Name: "table1", DependsOn: []string{"table2"}},
+				{Name: "table2", DependsOn: []string{"table3"}},
+				{Name: "table3", DependsOn: []string{"table1"}},
+			},
+			err: "dependency cycle table1 -> table2 -> table3 -> table1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tables.ValidateDependencies()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateDuplicateTables(t *testing.T) {
 	tests := []struct {
 		name   string
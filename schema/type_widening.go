@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"github.com/apache/arrow-go/v18/arrow"
+)
+
+// IsSafeTypeWidening reports whether all the values of type from can be represented by type to without loss,
+// so that destinations can migrate a column from one to the other while keeping its data.
+// Safe widenings are integers to larger integers of the same signedness, unsigned integers to larger signed integers,
+// integers to floating point types with a large enough mantissa, floating point types to larger ones, and strings and
+// binaries to their large variants.
+func IsSafeTypeWidening(from, to arrow.DataType) bool {
+	fromID, toID := from.ID(), to.ID()
+	switch {
+	case fromID == arrow.STRING && toID == arrow.LARGE_STRING,
+		fromID == arrow.BINARY && toID == arrow.LARGE_BINARY:
+		return true
+	case arrow.IsSignedInteger(fromID) && arrow.IsSignedInteger(toID),
+		arrow.IsUnsignedInteger(fromID) && arrow.IsUnsignedInteger(toID),
+		arrow.IsFloating(fromID) && arrow.IsFloating(toID):
+		return bitWidth(from) < bitWidth(to)
+	case arrow.IsUnsignedInteger(fromID) && arrow.IsSignedInteger(toID):
+		return bitWidth(from) < bitWidth(to)
+	case arrow.IsInteger(fromID) && arrow.IsFloating(toID):
+		// the mantissa of float32 has 24 bits, and the one of float64 has 53 bits
+		switch toID {
+		case arrow.FLOAT32:
+			return bitWidth(from) <= 16
+		case arrow.FLOAT64:
+			return bitWidth(from) <= 32
+		}
+	}
+	return false
+}
+
+func bitWidth(dt arrow.DataType) int {
+	if fw, ok := dt.(arrow.FixedWidthDataType); ok {
+		return fw.BitWidth()
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSafeTypeWidening(t *testing.T) {
+	tests := []struct {
+		from, to arrow.DataType
+		safe     bool
+	}{
+		{from: arrow.PrimitiveTypes.Int32, to: arrow.PrimitiveTypes.Int64, safe: true},
+		{from: arrow.PrimitiveTypes.Int64, to: arrow.PrimitiveTypes.Int32, safe: false},
+		{from: arrow.PrimitiveTypes.Uint32, to: arrow.PrimitiveTypes.Int64, safe: true},
+		{from: arrow.PrimitiveTypes.Uint32, to: arrow.PrimitiveTypes.Int32, safe: false},
+		{from: arrow.PrimitiveTypes.Int32, to: arrow.PrimitiveTypes.Uint64, safe: false},
+		{from: arrow.PrimitiveTypes.Int16, to: arrow.PrimitiveTypes.Float32, safe: true},
+		{from: arrow.PrimitiveTypes.Int32, to: arrow.PrimitiveTypes.Float32, safe: false},
+		{from: arrow.PrimitiveTypes.Int32, to: arrow.PrimitiveTypes.Float64, safe: true},
+		{from: arrow.PrimitiveTypes.Int64, to: arrow.PrimitiveTypes.Float64, safe: false},
+		{from: arrow.PrimitiveTypes.Float32, to: arrow.PrimitiveTypes.Float64, safe: true},
+		{from: arrow.BinaryTypes.String, to: arrow.BinaryTypes.LargeString, safe: true},
+		{from: arrow.BinaryTypes.String, to: arrow.BinaryTypes.Binary, safe: false},
+		{from: arrow.PrimitiveTypes.Int64, to: arrow.PrimitiveTypes.Int64, safe: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"_to_"+tt.to.String(), func(t *testing.T) {
+			require.Equal(t, tt.safe, IsSafeTypeWidening(tt.from, tt.to))
+		})
+	}
+}
+
+func TestGetChangesSummary_RenameAndWiden(t *testing.T) {
+	target := &Table{Name: "test", Columns: ColumnList{{Name: "count", Type: arrow.PrimitiveTypes.Int64, PreviousNames: []string{"total"}}}}
+	source := &Table{Name: "test", Columns: ColumnList{{Name: "total", Type: arrow.PrimitiveTypes.Int32}}}
+	summary := GetChangesSummary(map[string][]TableColumnChange{"test": target.GetChanges(source, WithColumnRenames(), WithTypeWidening())})
+	require.Equal(t, `test:
+  - Column "total" renamed to "count"
+  - Type widened from "int32" to "int64" for column "count"`, summary)
+}
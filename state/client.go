@@ -17,6 +17,8 @@ const defaultMaxMsgSizeInBytes = 100 * 1024 * 1024 // 100 MiB
 type Client interface {
 	SetKey(ctx context.Context, key string, value string) error
 	GetKey(ctx context.Context, key string) (string, error)
+	// DeleteKey removes the key from the state, once flushed.
+	DeleteKey(ctx context.Context, key string) error
 	Flush(ctx context.Context) error
 	Close() error
 }
@@ -95,6 +97,10 @@ func (*NoOpClient) GetKey(_ context.Context, _ string) (string, error) {
 	return "", nil
 }
 
+func (*NoOpClient) DeleteKey(_ context.Context, _ string) error {
+	return nil
+}
+
 func (*NoOpClient) Flush(_ context.Context) error {
 	return nil
 }
@@ -3,6 +3,7 @@ package batchwriter
 import (
 	"context"
 	"errors"
+	"os"
 	"sync"
 	"time"
 
@@ -37,6 +38,17 @@ type BatchWriter struct {
 	batchTimeout   time.Duration
 	batchSize      int64
 	batchSizeBytes int64
+	insertRetrier  *writers.InsertRetrier
+	limiter        *writers.ConcurrencyLimiter
+	deduplicator   *writers.PrimaryKeyDeduplicator
+	compactor      *writers.RecordCompactor
+
+	spillDir            string
+	spillMaxMemoryBytes int64
+	spiller             *spiller
+
+	insertErrsLock sync.Mutex
+	insertErrs     []error
 }
 
 // Assert at compile-time that BatchWriter implements the Writer interface
@@ -68,9 +80,67 @@ func WithBatchSizeBytes(size int64) Option {
 	}
 }
 
+// WithInsertRetrier retries, bisects and dead-letters the batches failing in WriteTableBatch.
+func WithInsertRetrier(retrier *writers.InsertRetrier) Option {
+	return func(p *BatchWriter) {
+		p.insertRetrier = retrier
+	}
+}
+
+// WithSpillToDisk stops the inserts from being back-pressured by a slow destination: they're queued without bounds,
+// and once the queued records exceed maxMemoryBytes, the next ones are spilled to Arrow IPC files in a temporary
+// directory created in dir, to be replayed in order. An empty dir means the default directory for temporary files.
+// The spill files are removed once replayed, and the directory when the writer is closed. Records that can't be
+// replayed are lost, and their error is returned by the next call to Write, Flush or Close.
+// maxMemoryBytes only bounds the queued records: every table worker also holds its pending batch, so the memory used
+// can reach maxMemoryBytes plus a batch of WithBatchSizeBytes per table.
+func WithSpillToDisk(dir string, maxMemoryBytes int64) Option {
+	return func(p *BatchWriter) {
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		p.spillDir = dir
+		p.spillMaxMemoryBytes = maxMemoryBytes
+	}
+}
+
+// WithConcurrencyLimiter limits the concurrent WriteTableBatch calls of the table workers.
+func WithConcurrencyLimiter(limiter *writers.ConcurrencyLimiter) Option {
+	return func(p *BatchWriter) {
+		p.limiter = limiter
+	}
+}
+
+// WithPrimaryKeyDeduplicator drops the rows having the same primary key within a batch before WriteTableBatch, keeping
+// the last one.
+func WithPrimaryKeyDeduplicator(deduplicator *writers.PrimaryKeyDeduplicator) Option {
+	return func(p *BatchWriter) {
+		p.deduplicator = deduplicator
+	}
+}
+
+// WithRecordCompactor merges the records of a batch into fewer, larger records before WriteTableBatch.
+func WithRecordCompactor(compactor *writers.RecordCompactor) Option {
+	return func(p *BatchWriter) {
+		p.compactor = compactor
+	}
+}
+
 type worker struct {
 	ch    chan *message.WriteInsert
 	flush chan chan bool
+	// queue buffers the inserts in front of ch in spill mode
+	queue *spillQueue
+}
+
+// flushAndWait sends all the queued inserts to the worker, and waits for it to write them.
+func (wr *worker) flushAndWait() {
+	if wr.queue != nil {
+		wr.queue.waitEmpty()
+	}
+	done := make(chan bool)
+	wr.flush <- done
+	<-done
 }
 
 const (
@@ -93,17 +163,34 @@ func New(client Client, opts ...Option) (*BatchWriter, error) {
 	}
 	c.migrateTableMessages = make([]*message.WriteMigrateTable, 0, c.batchSize)
 	c.deleteStaleMessages = make([]*message.WriteDeleteStale, 0, c.batchSize)
+	if c.spillDir != "" {
+		var err error
+		if c.spiller, err = newSpiller(c.spillDir, c.spillMaxMemoryBytes); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
+// SpillMetrics returns the metrics of the inserts spilled to disk, which are zero unless WithSpillToDisk is set.
+func (w *BatchWriter) SpillMetrics() SpillMetrics {
+	if w.spiller == nil {
+		return SpillMetrics{}
+	}
+	return w.spiller.metrics()
+}
+
+// Flush writes the pending messages. It returns the errors of the insert batches that failed to be written since the
+// last call to Flush, along with the errors of the pending messages.
 func (w *BatchWriter) Flush(ctx context.Context) error {
 	w.workersLock.RLock()
 	for _, worker := range w.workers {
-		done := make(chan bool)
-		worker.flush <- done
-		<-done
+		worker.flushAndWait()
 	}
 	w.workersLock.RUnlock()
+	if err := errors.Join(w.insertErr(), w.spillErr()); err != nil {
+		return err
+	}
 	if err := w.flushMigrateTables(ctx); err != nil {
 		return err
 	}
@@ -117,11 +204,35 @@ func (w *BatchWriter) Close(context.Context) error {
 	w.workersLock.Lock()
 	defer w.workersLock.Unlock()
 	for _, w := range w.workers {
-		close(w.ch)
+		if w.queue != nil {
+			w.queue.close()
+		} else {
+			close(w.ch)
+		}
 	}
 	w.workersWaitGroup.Wait()
 
-	return nil
+	if w.spiller != nil {
+		return errors.Join(w.insertErr(), w.spillErr(), w.spiller.close())
+	}
+	return w.insertErr()
+}
+
+// insertErr returns the errors of the insert batches that failed to be written since the last call.
+func (w *BatchWriter) insertErr() error {
+	w.insertErrsLock.Lock()
+	defer w.insertErrsLock.Unlock()
+	err := errors.Join(w.insertErrs...)
+	w.insertErrs = nil
+	return err
+}
+
+// spillErr returns the errors of the spilled records that couldn't be replayed since the last call.
+func (w *BatchWriter) spillErr() error {
+	if w.spiller == nil {
+		return nil
+	}
+	return w.spiller.takeErr()
 }
 
 func (w *BatchWriter) worker(ctx context.Context, tableName string, ch <-chan *message.WriteInsert, flush <-chan chan bool) {
@@ -199,10 +310,30 @@ func (w *BatchWriter) worker(ctx context.Context, tableName string, ch <-chan *m
 func (w *BatchWriter) flushTable(ctx context.Context, tableName string, resources message.WriteInserts, limit *batch.Cap) {
 	batchSize := limit.Rows()
 	start := time.Now()
-	err := w.client.WriteTableBatch(ctx, tableName, resources)
+	if w.deduplicator != nil {
+		resources = w.deduplicator.Deduplicate(resources)
+	}
+	if w.compactor != nil {
+		resources = w.compactor.Compact(resources)
+	}
+	var write writers.InsertBatchFunc = func(ctx context.Context, messages message.WriteInserts) error {
+		return w.client.WriteTableBatch(ctx, tableName, messages)
+	}
+	if w.limiter != nil {
+		write = w.limiter.LimitBatch(write)
+	}
+	var err error
+	if w.insertRetrier != nil {
+		err = w.insertRetrier.Write(ctx, resources, write)
+	} else {
+		err = write(ctx, resources)
+	}
 	duration := time.Since(start)
 	if err != nil {
 		w.logger.Err(err).Str("table", tableName).Int64("len", batchSize).Dur("duration", duration).Msg("failed to write batch")
+		w.insertErrsLock.Lock()
+		w.insertErrs = append(w.insertErrs, err)
+		w.insertErrsLock.Unlock()
 	} else {
 		w.logger.Debug().Str("table", tableName).Int64("len", batchSize).Dur("duration", duration).Msg("batch written successfully")
 	}
@@ -256,9 +387,7 @@ func (w *BatchWriter) flushInsert(tableName string) {
 		return
 	}
 	w.workersLock.RUnlock()
-	ch := make(chan bool)
-	worker.flush <- ch
-	<-ch
+	worker.flushAndWait()
 }
 
 func (w *BatchWriter) writeAll(ctx context.Context, msgs []message.WriteMessage) error {
@@ -317,6 +446,9 @@ func (w *BatchWriter) Write(ctx context.Context, msgs <-chan message.WriteMessag
 			if err := w.flushDeleteRecordTables(ctx); err != nil {
 				return err
 			}
+			if err := w.spillErr(); err != nil {
+				return err
+			}
 			if err := w.startWorker(ctx, m); err != nil {
 				return err
 			}
@@ -356,8 +488,7 @@ func (w *BatchWriter) startWorker(_ context.Context, msg *message.WriteInsert) e
 	wr, ok := w.workers[tableName]
 	w.workersLock.RUnlock()
 	if ok {
-		wr.ch <- msg
-		return nil
+		return wr.send(msg)
 	}
 	w.workersLock.Lock()
 	ch := make(chan *message.WriteInsert)
@@ -366,8 +497,21 @@ func (w *BatchWriter) startWorker(_ context.Context, msg *message.WriteInsert) e
 		ch:    ch,
 		flush: flush,
 	}
+	if w.spiller != nil {
+		wr.queue = newSpillQueue(w.spiller, tableName)
+	}
 	w.workers[tableName] = wr
 	w.workersLock.Unlock()
+	if wr.queue != nil {
+		w.workersWaitGroup.Add(1)
+		go func() {
+			defer w.workersWaitGroup.Done()
+			wr.queue.pump(ch, func(err error) {
+				w.logger.Err(err).Str("table", tableName).Msg("failed to replay spilled records")
+				w.spiller.fail(err)
+			})
+		}()
+	}
 	w.workersWaitGroup.Add(1)
 	go func() {
 		defer w.workersWaitGroup.Done()
@@ -375,6 +519,14 @@ func (w *BatchWriter) startWorker(_ context.Context, msg *message.WriteInsert) e
 		// w.cancelWorkers()
 		w.worker(context.Background(), tableName, ch, flush)
 	}()
-	ch <- msg
+	return wr.send(msg)
+}
+
+// send sends the insert to the worker, queueing it in spill mode.
+func (wr *worker) send(msg *message.WriteInsert) error {
+	if wr.queue != nil {
+		return wr.queue.push(msg.Record)
+	}
+	wr.ch <- msg
 	return nil
 }
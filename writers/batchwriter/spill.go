@@ -0,0 +1,355 @@
+package batchwriter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	meterName = "io.cloudquery"
+
+	spilledRowsMetricName  = "write.spill.rows"
+	spilledBytesMetricName = "write.spill.bytes"
+	spilledFilesMetricName = "write.spill.files"
+	replayedRowsMetricName = "write.spill.replayed_rows"
+)
+
+var (
+	spilledRowsCounter  metric.Int64Counter
+	spilledBytesCounter metric.Int64Counter
+	spilledFilesCounter metric.Int64Counter
+	replayedRowsCounter metric.Int64Counter
+	spillMetricsOnce    sync.Once
+)
+
+// SpillMetrics reports the inserts a BatchWriter spilled to disk.
+type SpillMetrics struct {
+	// SpilledRows is the number of rows written to spill files.
+	SpilledRows int64
+	// SpilledBytes is the in-memory size of the records written to spill files.
+	SpilledBytes int64
+	// SpilledFiles is the number of spill files created.
+	SpilledFiles int64
+	// ReplayedRows is the number of spilled rows read back and sent to the table workers.
+	ReplayedRows int64
+}
+
+// spiller holds the spill state shared by the queues of all the tables.
+type spiller struct {
+	dir            string
+	maxMemoryBytes int64
+	memoryBytes    atomic.Int64
+
+	spilledRows, spilledBytes, spilledFiles, replayedRows atomic.Int64
+
+	errsLock sync.Mutex
+	errs     []error
+}
+
+func newSpiller(dir string, maxMemoryBytes int64) (*spiller, error) {
+	spillMetricsOnce.Do(func() {
+		spilledRowsCounter, _ = otel.Meter(meterName).Int64Counter(spilledRowsMetricName,
+			metric.WithDescription("Number of rows spilled to disk while the destination was busy"),
+			metric.WithUnit("/{tot}"),
+		)
+		spilledBytesCounter, _ = otel.Meter(meterName).Int64Counter(spilledBytesMetricName,
+			metric.WithDescription("In-memory size of the records spilled to disk while the destination was busy"),
+			metric.WithUnit("By"),
+		)
+		spilledFilesCounter, _ = otel.Meter(meterName).Int64Counter(spilledFilesMetricName,
+			metric.WithDescription("Number of spill files created"),
+			metric.WithUnit("/{tot}"),
+		)
+		replayedRowsCounter, _ = otel.Meter(meterName).Int64Counter(replayedRowsMetricName,
+			metric.WithDescription("Number of spilled rows replayed to the destination"),
+			metric.WithUnit("/{tot}"),
+		)
+	})
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(dir, "cq-batchwriter-spill-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	return &spiller{dir: dir, maxMemoryBytes: maxMemoryBytes}, nil
+}
+
+// reserve reserves size bytes of the memory budget, returning false if there isn't enough left.
+func (s *spiller) reserve(size int64) bool {
+	for {
+		current := s.memoryBytes.Load()
+		if current+size > s.maxMemoryBytes {
+			return false
+		}
+		if s.memoryBytes.CompareAndSwap(current, current+size) {
+			return true
+		}
+	}
+}
+
+func (s *spiller) release(size int64) {
+	s.memoryBytes.Add(-size)
+}
+
+func (s *spiller) metrics() SpillMetrics {
+	return SpillMetrics{
+		SpilledRows:  s.spilledRows.Load(),
+		SpilledBytes: s.spilledBytes.Load(),
+		SpilledFiles: s.spilledFiles.Load(),
+		ReplayedRows: s.replayedRows.Load(),
+	}
+}
+
+// fail records the error of spilled records that couldn't be replayed, to be returned by takeErr.
+func (s *spiller) fail(err error) {
+	s.errsLock.Lock()
+	defer s.errsLock.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+// takeErr returns the errors recorded by fail since the last call.
+func (s *spiller) takeErr() error {
+	s.errsLock.Lock()
+	defer s.errsLock.Unlock()
+	err := errors.Join(s.errs...)
+	s.errs = nil
+	return err
+}
+
+func (s *spiller) close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// spillQueue is the unbounded FIFO queue of the inserts of a table in spill mode. Records are kept in memory while
+// the memory budget of the writer allows it, and are written to Arrow IPC files otherwise, to be replayed in order.
+type spillQueue struct {
+	spiller   *spiller
+	tableName string
+	attrs     metric.MeasurementOption
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    []*spillItem
+	inflight bool
+	closed   bool
+	files    int
+}
+
+type spillItem struct {
+	// record and bytes are set for records kept in memory
+	record arrow.RecordBatch
+	bytes  int64
+	// segment is set for records spilled to disk
+	segment *spillSegment
+}
+
+type spillSegment struct {
+	path   string
+	schema *arrow.Schema
+	file   *os.File
+	writer *ipc.Writer // nil once the segment is sealed
+	reader *ipc.Reader // set once the segment is being replayed
+}
+
+func newSpillQueue(s *spiller, tableName string) *spillQueue {
+	q := &spillQueue{
+		spiller:   s,
+		tableName: tableName,
+		attrs:     metric.WithAttributeSet(attribute.NewSet(attribute.Key("sync.table.name").String(tableName))),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues the record without blocking on the worker, spilling it to disk if the memory budget is exhausted.
+// Once a record was spilled, the next ones are spilled as well until the worker catches up with the spill file.
+func (q *spillQueue) push(record arrow.RecordBatch) error {
+	size := util.TotalRecordSize(record)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var tail *spillItem
+	if len(q.items) > 0 {
+		tail = q.items[len(q.items)-1]
+	}
+	spilling := tail != nil && tail.segment != nil && tail.segment.writer != nil
+	if !spilling && q.spiller.reserve(size) {
+		q.items = append(q.items, &spillItem{record: record, bytes: size})
+		q.cond.Broadcast()
+		return nil
+	}
+
+	if !spilling || !tail.segment.schema.Equal(record.Schema()) {
+		segment, err := q.newSegment(record.Schema())
+		if err != nil {
+			return err
+		}
+		tail = &spillItem{segment: segment}
+		q.items = append(q.items, tail)
+	}
+	if err := tail.segment.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to spill records of table %s: %w", q.tableName, err)
+	}
+	q.spiller.spilledRows.Add(record.NumRows())
+	q.spiller.spilledBytes.Add(size)
+	spilledRowsCounter.Add(context.Background(), record.NumRows(), q.attrs)
+	spilledBytesCounter.Add(context.Background(), size, q.attrs)
+	q.cond.Broadcast()
+	return nil
+}
+
+func (q *spillQueue) newSegment(sc *arrow.Schema) (*spillSegment, error) {
+	q.files++
+	path := filepath.Join(q.spiller.dir, fmt.Sprintf("%s-%d.arrows", q.tableName, q.files))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file for table %s: %w", q.tableName, err)
+	}
+	q.spiller.spilledFiles.Add(1)
+	spilledFilesCounter.Add(context.Background(), 1, q.attrs)
+	return &spillSegment{
+		path:   path,
+		schema: sc,
+		file:   f,
+		writer: ipc.NewWriter(f, ipc.WithSchema(sc), ipc.WithAllocator(memory.DefaultAllocator)),
+	}, nil
+}
+
+// pop returns the next record, waiting for one to be pushed. It returns false once the queue is closed and empty.
+// Records that can't be read back from a spill file are reported with onError and skipped.
+// The memory reserved for a record is released once it's popped, although the worker holds it until its batch is
+// written.
+func (q *spillQueue) pop(onError func(error)) (arrow.RecordBatch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for len(q.items) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.items) == 0 {
+			return nil, false
+		}
+
+		head := q.items[0]
+		if head.segment == nil {
+			q.items = q.items[1:]
+			q.spiller.release(head.bytes)
+			q.inflight = true
+			return head.record, true
+		}
+
+		record, err := head.segment.next()
+		if err != nil {
+			onError(fmt.Errorf("failed to replay spilled records of table %s: %w", q.tableName, err))
+		}
+		if record == nil {
+			// the spill directory is removed on close if the segment can't be
+			_ = head.segment.remove()
+			q.items = q.items[1:]
+			q.cond.Broadcast()
+			continue
+		}
+		q.spiller.replayedRows.Add(record.NumRows())
+		replayedRowsCounter.Add(context.Background(), record.NumRows(), q.attrs)
+		q.inflight = true
+		return record, true
+	}
+}
+
+// done marks the record returned by the last pop as received by the worker.
+func (q *spillQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inflight = false
+	q.cond.Broadcast()
+}
+
+// waitEmpty waits for all the queued records to be received by the worker.
+func (q *spillQueue) waitEmpty() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) > 0 || q.inflight {
+		q.cond.Wait()
+	}
+}
+
+func (q *spillQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// pump sends the records of the queue to the worker channel, closing it once the queue is closed and drained.
+func (q *spillQueue) pump(ch chan<- *message.WriteInsert, onError func(error)) {
+	defer close(ch)
+	for {
+		record, ok := q.pop(onError)
+		if !ok {
+			return
+		}
+		ch <- &message.WriteInsert{Record: record}
+		q.done()
+	}
+}
+
+// next returns the next record of the segment, sealing it and opening it for reading first if needed.
+// It returns a nil record once the segment is exhausted.
+func (s *spillSegment) next() (arrow.RecordBatch, error) {
+	if s.reader == nil {
+		if err := s.seal(); err != nil {
+			return nil, err
+		}
+		f, err := os.Open(s.path)
+		if err != nil {
+			return nil, err
+		}
+		s.file = f
+		if s.reader, err = ipc.NewReader(f, ipc.WithAllocator(memory.DefaultAllocator)); err != nil {
+			return nil, err
+		}
+	}
+	if !s.reader.Next() {
+		return nil, s.reader.Err()
+	}
+	record := s.reader.RecordBatch()
+	record.Retain()
+	return record, nil
+}
+
+func (s *spillSegment) seal() error {
+	if s.writer == nil {
+		return nil
+	}
+	err := errors.Join(s.writer.Close(), s.file.Close())
+	s.writer, s.file = nil, nil
+	return err
+}
+
+func (s *spillSegment) remove() error {
+	var errs []error
+	errs = append(errs, s.seal())
+	if s.reader != nil {
+		s.reader.Release()
+	}
+	if s.file != nil {
+		errs = append(errs, s.file.Close())
+	}
+	errs = append(errs, os.Remove(s.path))
+	return errors.Join(errs...)
+}
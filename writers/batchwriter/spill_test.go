@@ -0,0 +1,187 @@
+package batchwriter
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// slowBatchClient blocks WriteTableBatch until unblock is closed, and records the written ids in order.
+type slowBatchClient struct {
+	testBatchClient
+	unblock chan struct{}
+
+	idsLock sync.Mutex
+	ids     []int64
+}
+
+func (c *slowBatchClient) WriteTableBatch(_ context.Context, _ string, messages message.WriteInserts) error {
+	<-c.unblock
+	c.idsLock.Lock()
+	defer c.idsLock.Unlock()
+	for _, msg := range messages {
+		c.ids = append(c.ids, msg.Record.Column(0).(*array.Int64).Int64Values()...)
+	}
+	return nil
+}
+
+func idRecord(sc *arrow.Schema, ids ...int64) arrow.RecordBatch {
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	return bldr.NewRecordBatch()
+}
+
+func TestBatchSpillToDisk(t *testing.T) {
+	for name, tc := range map[string]struct {
+		maxMemoryBytes int64
+		spilled        bool
+	}{
+		"spilled":   {maxMemoryBytes: 1, spilled: true},
+		"in_memory": {maxMemoryBytes: 1024 * 1024},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			testClient := &slowBatchClient{unblock: make(chan struct{})}
+			wr, err := New(testClient, WithBatchSize(2), WithSpillToDisk(dir, tc.maxMemoryBytes))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sc := batchTestTables[0].ToArrowSchema()
+			var msgs []message.WriteMessage
+			var expected []int64
+			for i := int64(0); i < 20; i++ {
+				msgs = append(msgs, &message.WriteInsert{Record: idRecord(sc, i)})
+				expected = append(expected, i)
+			}
+
+			// the destination is blocked, the inserts must be queued without back-pressure
+			written := make(chan error)
+			go func() { written <- wr.writeAll(ctx, msgs) }()
+			select {
+			case err := <-written:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("write was back-pressured by the destination")
+			}
+
+			metrics := wr.SpillMetrics()
+			if tc.spilled != (metrics.SpilledFiles > 0) {
+				t.Fatalf("unexpected spill metrics %+v", metrics)
+			}
+
+			close(testClient.unblock)
+			if err := wr.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(expected, testClient.ids) {
+				t.Fatalf("expected ids %v, got %v", expected, testClient.ids)
+			}
+
+			metrics = wr.SpillMetrics()
+			if metrics.SpilledRows != metrics.ReplayedRows {
+				t.Fatalf("expected all the spilled rows to be replayed, got %+v", metrics)
+			}
+			if tc.spilled && metrics.SpilledBytes == 0 {
+				t.Fatalf("expected spilled bytes, got %+v", metrics)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("expected the spill directory to be removed, got %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestBatchSpillFlush(t *testing.T) {
+	ctx := context.Background()
+	testClient := &slowBatchClient{unblock: make(chan struct{})}
+	close(testClient.unblock)
+	wr, err := New(testClient, WithSpillToDisk(t.TempDir(), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := batchTestTables[0].ToArrowSchema()
+	if err := wr.writeAll(ctx, []message.WriteMessage{
+		&message.WriteInsert{Record: idRecord(sc, 1, 2)},
+		&message.WriteInsert{Record: idRecord(sc, 3)},
+		// migrating the table flushes the queued inserts
+		&message.WriteMigrateTable{Table: &schema.Table{Name: batchTestTables[0].Name}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	testClient.idsLock.Lock()
+	ids := slices.Clone(testClient.ids)
+	testClient.idsLock.Unlock()
+	if !slices.Equal([]int64{1, 2, 3}, ids) {
+		t.Fatalf("expected ids [1 2 3], got %v", ids)
+	}
+	if err := wr.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchSpillReplayError(t *testing.T) {
+	ctx := context.Background()
+	testClient := &slowBatchClient{unblock: make(chan struct{})}
+	close(testClient.unblock)
+	wr, err := New(testClient, WithSpillToDisk(t.TempDir(), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := batchTestTables[0].ToArrowSchema()
+	if err := wr.writeAll(ctx, []message.WriteMessage{&message.WriteInsert{Record: idRecord(sc, 1)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// queue a spill file that can't be read back
+	q := wr.workers[batchTestTables[0].Name].queue
+	q.mu.Lock()
+	segment, err := q.newSegment(sc)
+	if err != nil {
+		q.mu.Unlock()
+		t.Fatal(err)
+	}
+	err = segment.writer.Write(idRecord(sc, 2))
+	if err == nil {
+		err = os.WriteFile(segment.path, []byte("corrupted"), 0o644)
+	}
+	q.items = append(q.items, &spillItem{segment: segment})
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wr.Flush(ctx); err == nil {
+		t.Fatal("expected the replay error to be returned by Flush")
+	}
+	if err := wr.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal([]int64{1}, testClient.ids) {
+		t.Fatalf("expected ids [1], got %v", testClient.ids)
+	}
+}
@@ -0,0 +1,186 @@
+package writers
+
+import (
+	"context"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+)
+
+// RecordCompactor concatenates the records of insert batches into fewer, larger records, for destinations paying a
+// per-record overhead. The records of every table are merged in order, and only consecutive records of a table with
+// the same schema are merged together.
+// Compaction happens once a writer has cut its batch, so the batch size options still bound the merged records, and
+// MaxRows and MaxBytes can only make them smaller.
+type RecordCompactor struct {
+	// MaxRows is the maximum number of rows of a merged record. 0 means no limit besides the batch size of the writer.
+	MaxRows int64
+	// MaxBytes is the maximum estimated size of a merged record. 0 means no limit besides the batch size of the writer.
+	MaxBytes int64
+	Logger   zerolog.Logger
+}
+
+// Compact returns the inserts with their records merged, grouped by table in the order the tables first appear.
+// Records that can't be merged are returned as-is.
+func (c *RecordCompactor) Compact(messages message.WriteInserts) message.WriteInserts {
+	if len(messages) < 2 {
+		return messages
+	}
+	var tableNames []string
+	tables := make(map[string][]arrow.RecordBatch)
+	for _, msg := range messages {
+		tableName, _ := msg.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+		if _, ok := tables[tableName]; !ok {
+			tableNames = append(tableNames, tableName)
+		}
+		tables[tableName] = append(tables[tableName], msg.Record)
+	}
+
+	compacted := make(message.WriteInserts, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		m := c.newMerger(tableName, func(record arrow.RecordBatch) {
+			compacted = append(compacted, &message.WriteInsert{Record: record})
+		})
+		for _, record := range tables[tableName] {
+			m.add(record)
+		}
+		m.flush()
+	}
+	return compacted
+}
+
+// WrapBatch returns an InsertBatchFunc that compacts the batch before writing it with write.
+func (c *RecordCompactor) WrapBatch(write InsertBatchFunc) InsertBatchFunc {
+	return func(ctx context.Context, messages message.WriteInserts) error {
+		return write(ctx, c.Compact(messages))
+	}
+}
+
+// WrapStream returns an InsertStreamFunc that compacts the inserts sent on the channel as they come, sending them to
+// write on a new channel. A merged record is sent once MaxRows or MaxBytes would be exceeded, or the schema changes,
+// so only the record being merged is buffered: without MaxRows and MaxBytes, that's the whole batch.
+// All the inserts sent on the channel must be of the same table, as they are for the handlers of streamingbatchwriter.
+func (c *RecordCompactor) WrapStream(write InsertStreamFunc) InsertStreamFunc {
+	return func(ctx context.Context, ch <-chan *message.WriteInsert) error {
+		compacted := make(chan *message.WriteInsert)
+		done := make(chan struct{})
+		var err error
+		go func() {
+			defer close(done)
+			err = write(ctx, compacted)
+		}()
+
+		var m *recordMerger
+		stopped := false
+		for msg := range ch {
+			if m == nil {
+				tableName, _ := msg.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+				m = c.newMerger(tableName, func(record arrow.RecordBatch) {
+					select {
+					case compacted <- &message.WriteInsert{Record: record}:
+					case <-done:
+						// write returned early, its error is returned once the channel is drained
+						stopped = true
+					}
+				})
+			}
+			if !stopped {
+				m.add(msg.Record)
+			}
+		}
+		if m != nil && !stopped {
+			m.flush()
+		}
+		close(compacted)
+		<-done
+		return err
+	}
+}
+
+// recordMerger merges the consecutive records of a table with the same schema, within the limits of the compactor.
+type recordMerger struct {
+	c         *RecordCompactor
+	tableName string
+	emit      func(arrow.RecordBatch)
+
+	pending     []arrow.RecordBatch
+	rows, bytes int64
+}
+
+func (c *RecordCompactor) newMerger(tableName string, emit func(arrow.RecordBatch)) *recordMerger {
+	return &recordMerger{c: c, tableName: tableName, emit: emit}
+}
+
+// add merges the record with the pending ones, emitting them first if the record can't be merged with them.
+func (m *recordMerger) add(record arrow.RecordBatch) {
+	size := util.TotalRecordSize(record)
+	if len(m.pending) > 0 && (!sameSchema(m.pending[0].Schema(), record.Schema()) || m.c.exceeds(m.rows+record.NumRows(), m.bytes+size)) {
+		m.flush()
+	}
+	m.pending = append(m.pending, record)
+	m.rows += record.NumRows()
+	m.bytes += size
+}
+
+// flush emits the pending records merged in a single record, or as-is if they can't be merged.
+func (m *recordMerger) flush() {
+	pending := m.pending
+	m.pending, m.rows, m.bytes = nil, 0, 0
+	switch len(pending) {
+	case 0:
+		return
+	case 1:
+		m.emit(pending[0])
+		return
+	}
+	record, err := concatenateRecords(pending)
+	if err != nil {
+		m.c.Logger.Warn().Err(err).Str("table", m.tableName).Msg("failed to merge records, writing them as-is")
+		for _, record := range pending {
+			m.emit(record)
+		}
+		return
+	}
+	m.emit(record)
+}
+
+func (c *RecordCompactor) exceeds(rows, bytes int64) bool {
+	return (c.MaxRows > 0 && rows > c.MaxRows) || (c.MaxBytes > 0 && bytes > c.MaxBytes)
+}
+
+// sameSchema reports whether records with the schemas can be merged, including their metadata.
+func sameSchema(a, b *arrow.Schema) bool {
+	return a.Equal(b) && a.Metadata().Equal(b.Metadata())
+}
+
+// concatenateRecords merges records having the same schema into a single one.
+func concatenateRecords(records []arrow.RecordBatch) (arrow.RecordBatch, error) {
+	sc := records[0].Schema()
+	cols := make([]arrow.Array, 0, sc.NumFields())
+	defer func() {
+		for _, col := range cols {
+			col.Release()
+		}
+	}()
+	var rows int64
+	for _, record := range records {
+		rows += record.NumRows()
+	}
+	arrs := make([]arrow.Array, len(records))
+	for i := range sc.NumFields() {
+		for j, record := range records {
+			arrs[j] = record.Column(i)
+		}
+		col, err := array.Concatenate(arrs, memory.DefaultAllocator)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return array.NewRecordBatch(sc, cols, rows), nil
+}
@@ -0,0 +1,175 @@
+package writers_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/cloudquery/plugin-sdk/v4/writers"
+	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/mixedbatchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/streamingbatchwriter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func recordRows(messages message.WriteInserts) []int64 {
+	rows := make([]int64, len(messages))
+	for i, msg := range messages {
+		rows[i] = msg.Record.NumRows()
+	}
+	return rows
+}
+
+func TestRecordCompactor(t *testing.T) {
+	c := &writers.RecordCompactor{MaxRows: 4}
+	messages := message.WriteInserts{
+		{Record: pkRecord("table_a", false, []int64{1, 2}, []string{"a", "b"})},
+		{Record: pkRecord("table_b", false, []int64{1}, []string{"c"})},
+		{Record: pkRecord("table_a", false, []int64{3}, []string{"d"})},
+		{Record: pkRecord("table_a", false, []int64{4, 5}, []string{"e", "f"})},
+		// a different schema isn't merged with the other records of the table
+		{Record: pkRecord("table_a", true, []int64{6}, []string{"g"})},
+		{Record: pkRecord("table_a", true, []int64{7}, []string{"h"})},
+		{Record: pkRecord("table_b", false, []int64{2}, []string{"i"})},
+	}
+	compacted := c.Compact(messages)
+	require.Equal(t, []int64{3, 2, 2, 2}, recordRows(compacted))
+	require.Equal(t, []string{
+		"table_a:a", "table_a:b", "table_a:d",
+		"table_a:e", "table_a:f",
+		"table_a:g", "table_a:h",
+		"table_b:c", "table_b:i",
+	}, insertValues(compacted))
+	require.True(t, compacted[2].Record.Schema().Equal(messages[4].Record.Schema()))
+}
+
+func TestRecordCompactor_ExtensionTypes(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "uuid", Type: types.ExtensionTypes.UUID}}}
+	var messages message.WriteInserts
+	var expected []string
+	for range 3 {
+		bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+		id := uuid.New()
+		bldr.Field(0).(*types.UUIDBuilder).Append(id)
+		messages = append(messages, &message.WriteInsert{Record: bldr.NewRecordBatch()})
+		bldr.Release()
+		expected = append(expected, id.String())
+	}
+
+	compacted := (&writers.RecordCompactor{}).Compact(messages)
+	require.Len(t, compacted, 1)
+	col := compacted[0].Record.Column(0)
+	require.True(t, arrow.TypeEqual(types.ExtensionTypes.UUID, col.DataType()))
+	for i, id := range expected {
+		require.Equal(t, id, col.ValueStr(i))
+	}
+}
+
+func TestRecordCompactor_WrapStream(t *testing.T) {
+	ctx := context.Background()
+	received := make(chan int64)
+	write := (&writers.RecordCompactor{MaxRows: 2}).WrapStream(func(_ context.Context, ch <-chan *message.WriteInsert) error {
+		defer close(received)
+		for msg := range ch {
+			received <- msg.Record.NumRows()
+		}
+		return nil
+	})
+	ch := make(chan *message.WriteInsert)
+	errCh := make(chan error, 1)
+	go func() { errCh <- write(ctx, ch) }()
+
+	for i := range int64(3) {
+		ch <- &message.WriteInsert{Record: pkRecord("test_table", false, []int64{i}, []string{"a"})}
+	}
+	// the first records are merged and sent while the stream is still open
+	require.EqualValues(t, 2, <-received)
+	for i := range int64(2) {
+		ch <- &message.WriteInsert{Record: pkRecord("test_table", false, []int64{i}, []string{"a"})}
+	}
+	close(ch)
+	require.EqualValues(t, 2, <-received)
+	require.EqualValues(t, 1, <-received)
+	_, ok := <-received
+	require.False(t, ok)
+	require.NoError(t, <-errCh)
+}
+
+func TestRecordCompactor_WrapStreamError(t *testing.T) {
+	errWrite := errors.New("write failed")
+	write := (&writers.RecordCompactor{MaxRows: 1}).WrapStream(func(context.Context, <-chan *message.WriteInsert) error {
+		return errWrite
+	})
+	ch := make(chan *message.WriteInsert, 3)
+	for i := range int64(3) {
+		ch <- &message.WriteInsert{Record: pkRecord("test_table", false, []int64{i}, []string{"a"})}
+	}
+	close(ch)
+	require.ErrorIs(t, write(context.Background(), ch), errWrite)
+	require.Empty(t, ch)
+}
+
+// recordCounter records the values and the number of records written.
+type recordCounter struct {
+	mu      sync.Mutex
+	records int
+	values  []string
+}
+
+func (c *recordCounter) write(_ context.Context, messages message.WriteInserts) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records += len(messages)
+	c.values = append(c.values, insertValues(messages)...)
+	return nil
+}
+
+func TestWithRecordCompactor(t *testing.T) {
+	type closer interface {
+		Close(context.Context) error
+	}
+	cases := map[string]func(write writers.InsertBatchFunc, c *writers.RecordCompactor) (writers.Writer, error){
+		"BatchWriter": func(write writers.InsertBatchFunc, c *writers.RecordCompactor) (writers.Writer, error) {
+			return batchwriter.New(insertFuncBatchClient{write: write}, batchwriter.WithRecordCompactor(c))
+		},
+		"MixedBatchWriter": func(write writers.InsertBatchFunc, c *writers.RecordCompactor) (writers.Writer, error) {
+			return mixedbatchwriter.New(insertFuncMixedClient{write: write}, mixedbatchwriter.WithRecordCompactor(c))
+		},
+		"StreamingBatchWriter": func(write writers.InsertBatchFunc, c *writers.RecordCompactor) (writers.Writer, error) {
+			return streamingbatchwriter.New(insertFuncStreamingClient{write: write}, streamingbatchwriter.WithRecordCompactor(c))
+		},
+	}
+	for name, newWriter := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := &recordCounter{}
+			wr, err := newWriter(c.write, &writers.RecordCompactor{})
+			require.NoError(t, err)
+
+			ch := make(chan message.WriteMessage, 10)
+			var expected []string
+			for i := range int64(10) {
+				value := strconv.FormatInt(i, 10)
+				ch <- &message.WriteInsert{Record: pkRecord("test_table", false, []int64{i}, []string{value})}
+				expected = append(expected, "test_table:"+value)
+			}
+			close(ch)
+			require.NoError(t, wr.Write(ctx, ch))
+			if c, ok := wr.(closer); ok {
+				require.NoError(t, c.Close(ctx))
+			}
+
+			require.Equal(t, expected, c.values)
+			require.Equal(t, 1, c.records)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package writers
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"golang.org/x/sync/semaphore"
+)
+
+// ConcurrencyLimiter limits the number of in-flight insert writes, overall and per table, so that destinations with
+// limited connection pools aren't overwhelmed when many tables are written at once.
+// The limits apply across all the writers the limiter is set on, so a destination creating several writers should give
+// them the same limiter to bound the total number of connections it uses.
+type ConcurrencyLimiter struct {
+	all         *semaphore.Weighted
+	maxPerTable int64
+	tableLimits map[string]int64
+
+	mu     sync.Mutex
+	tables map[string]*semaphore.Weighted
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter allowing at most maxConcurrent in-flight writes overall, and
+// maxPerTable for every table. tableLimits overrides maxPerTable for specific tables. A limit of 0 means no limit.
+func NewConcurrencyLimiter(maxConcurrent, maxPerTable int64, tableLimits map[string]int64) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{
+		maxPerTable: maxPerTable,
+		tableLimits: tableLimits,
+		tables:      make(map[string]*semaphore.Weighted),
+	}
+	if maxConcurrent > 0 {
+		l.all = semaphore.NewWeighted(maxConcurrent)
+	}
+	return l
+}
+
+// Acquire waits for a write of the given tables to be allowed, and returns the function releasing it.
+// The tables are acquired in order, then a single overall slot is.
+// It returns an error if ctx is done first, in which case nothing stays acquired.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, tableNames ...string) (func(), error) {
+	tableNames = slices.Clone(tableNames)
+	slices.Sort(tableNames)
+	tableNames = slices.Compact(tableNames)
+
+	var acquired []*semaphore.Weighted
+	release := func() {
+		for _, sem := range acquired {
+			sem.Release(1)
+		}
+	}
+	for _, tableName := range tableNames {
+		sem := l.tableSemaphore(tableName)
+		if sem == nil {
+			continue
+		}
+		if err := sem.Acquire(ctx, 1); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, sem)
+	}
+	if l.all != nil {
+		if err := l.all.Acquire(ctx, 1); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, l.all)
+	}
+	return release, nil
+}
+
+// LimitBatch returns an InsertBatchFunc that waits for the tables of the batch to be allowed before calling write.
+func (l *ConcurrencyLimiter) LimitBatch(write InsertBatchFunc) InsertBatchFunc {
+	return func(ctx context.Context, messages message.WriteInserts) error {
+		tableNames := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			tableName, _ := msg.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+			tableNames = append(tableNames, tableName)
+		}
+		release, err := l.Acquire(ctx, tableNames...)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return write(ctx, messages)
+	}
+}
+
+// LimitStream returns an InsertStreamFunc that waits for tableName to be allowed before calling write.
+func (l *ConcurrencyLimiter) LimitStream(tableName string, write InsertStreamFunc) InsertStreamFunc {
+	return func(ctx context.Context, messages <-chan *message.WriteInsert) error {
+		release, err := l.Acquire(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return write(ctx, messages)
+	}
+}
+
+// tableSemaphore returns the semaphore of the table, or nil if the table has no limit.
+func (l *ConcurrencyLimiter) tableSemaphore(tableName string) *semaphore.Weighted {
+	limit, ok := l.tableLimits[tableName]
+	if !ok {
+		limit = l.maxPerTable
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sem, ok := l.tables[tableName]
+	if !ok {
+		sem = semaphore.NewWeighted(limit)
+		l.tables[tableName] = sem
+	}
+	return sem
+}
@@ -0,0 +1,143 @@
+package writers_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/writers"
+	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/mixedbatchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/streamingbatchwriter"
+	"github.com/stretchr/testify/require"
+)
+
+// inFlight tracks the maximum number of concurrent calls, overall and per table.
+type inFlight struct {
+	mu         sync.Mutex
+	current    int
+	max        int
+	tables     map[string]int
+	maxByTable map[string]int
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{tables: make(map[string]int), maxByTable: make(map[string]int)}
+}
+
+func (f *inFlight) call(tableName string) {
+	f.mu.Lock()
+	f.current++
+	f.max = max(f.max, f.current)
+	f.tables[tableName]++
+	f.maxByTable[tableName] = max(f.maxByTable[tableName], f.tables[tableName])
+	f.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	f.mu.Lock()
+	f.current--
+	f.tables[tableName]--
+	f.mu.Unlock()
+}
+
+func tableRecord(tableName string) arrow.RecordBatch {
+	table := &schema.Table{Name: tableName, Columns: schema.ColumnList{{Name: "value", Type: arrow.PrimitiveTypes.Int64}}}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).Append(1)
+	return bldr.NewRecordBatch()
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := writers.NewConcurrencyLimiter(3, 1, map[string]int64{"table_b": 2})
+	calls := newInFlight()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		tableName := []string{"table_a", "table_b", "table_c", "table_d"}[i%4]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(ctx, tableName)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			calls.call(tableName)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 3, calls.max)
+	require.Equal(t, 1, calls.maxByTable["table_a"])
+	require.Equal(t, 2, calls.maxByTable["table_b"])
+}
+
+func TestConcurrencyLimiter_ContextDone(t *testing.T) {
+	limiter := writers.NewConcurrencyLimiter(1, 0, nil)
+	release, err := limiter.Acquire(context.Background(), "table")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx, "table")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = limiter.Acquire(context.Background(), "table")
+	require.NoError(t, err)
+	release()
+}
+
+func TestWithConcurrencyLimiter(t *testing.T) {
+	type closer interface {
+		Close(context.Context) error
+	}
+	cases := map[string]func(write writers.InsertBatchFunc, limiter *writers.ConcurrencyLimiter) (writers.Writer, error){
+		"BatchWriter": func(write writers.InsertBatchFunc, limiter *writers.ConcurrencyLimiter) (writers.Writer, error) {
+			return batchwriter.New(insertFuncBatchClient{write: write}, batchwriter.WithBatchSize(1), batchwriter.WithConcurrencyLimiter(limiter))
+		},
+		"MixedBatchWriter": func(write writers.InsertBatchFunc, limiter *writers.ConcurrencyLimiter) (writers.Writer, error) {
+			return mixedbatchwriter.New(insertFuncMixedClient{write: write}, mixedbatchwriter.WithBatchSize(1), mixedbatchwriter.WithConcurrencyLimiter(limiter))
+		},
+		"StreamingBatchWriter": func(write writers.InsertBatchFunc, limiter *writers.ConcurrencyLimiter) (writers.Writer, error) {
+			return streamingbatchwriter.New(insertFuncStreamingClient{write: write}, streamingbatchwriter.WithBatchSizeRows(1), streamingbatchwriter.WithConcurrencyLimiter(limiter))
+		},
+	}
+	for name, newWriter := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			calls := newInFlight()
+			write := func(_ context.Context, messages message.WriteInserts) error {
+				tableName, _ := messages[0].Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+				calls.call(tableName)
+				return nil
+			}
+			wr, err := newWriter(write, writers.NewConcurrencyLimiter(2, 0, nil))
+			require.NoError(t, err)
+
+			ch := make(chan message.WriteMessage, 20)
+			for i := 0; i < 20; i++ {
+				ch <- &message.WriteInsert{Record: tableRecord(fmt.Sprintf("table_%d", i%10))}
+			}
+			close(ch)
+			require.NoError(t, wr.Write(ctx, ch))
+			if c, ok := wr.(closer); ok {
+				require.NoError(t, c.Close(ctx))
+			}
+
+			require.LessOrEqual(t, calls.max, 2)
+			require.Positive(t, calls.max)
+		})
+	}
+}
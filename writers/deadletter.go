@@ -0,0 +1,125 @@
+package writers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// DeadLetterErrorColumn is the column holding the write error of every row in dead-letter files.
+const DeadLetterErrorColumn = "_cq_dead_letter_error"
+
+// DeadLetter stores the rows that couldn't be written.
+type DeadLetter interface {
+	// WriteDeadLetter stores the rows of record, which couldn't be written because of err.
+	// It may be called concurrently.
+	WriteDeadLetter(record arrow.RecordBatch, err error) error
+}
+
+// DeadLetterFile is a DeadLetter writing the rows to Arrow IPC stream files in a directory, with an additional
+// DeadLetterErrorColumn. A file named after the table and the time it was created is written for every table, and a
+// new one is started if the schema of the table changes. Files must be closed with Close to be complete.
+type DeadLetterFile struct {
+	dir string
+
+	mu      sync.Mutex
+	streams map[string]*deadLetterStream
+}
+
+type deadLetterStream struct {
+	file   *os.File
+	writer *ipc.Writer
+	schema *arrow.Schema
+}
+
+// Assert at compile-time that DeadLetterFile implements the DeadLetter interface
+var _ DeadLetter = (*DeadLetterFile)(nil)
+
+// NewDeadLetterFile returns a DeadLetterFile writing to dir, which is created if needed.
+func NewDeadLetterFile(dir string) (*DeadLetterFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	return &DeadLetterFile{dir: dir, streams: make(map[string]*deadLetterStream)}, nil
+}
+
+func (d *DeadLetterFile) WriteDeadLetter(record arrow.RecordBatch, err error) error {
+	record = withErrorColumn(record, err)
+	defer record.Release()
+	tableName, _ := record.Schema().Metadata().GetValue(schema.MetadataTableName)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stream, ok := d.streams[tableName]
+	if ok && !stream.schema.Equal(record.Schema()) {
+		if err := stream.close(); err != nil {
+			return err
+		}
+		ok = false
+	}
+	if !ok {
+		var err error
+		if stream, err = d.newStream(tableName, record.Schema()); err != nil {
+			return err
+		}
+		d.streams[tableName] = stream
+	}
+	return stream.writer.Write(record)
+}
+
+// Close completes and closes all the files.
+func (d *DeadLetterFile) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var errs []error
+	for tableName, stream := range d.streams {
+		errs = append(errs, stream.close())
+		delete(d.streams, tableName)
+	}
+	return errors.Join(errs...)
+}
+
+func (d *DeadLetterFile) newStream(tableName string, sc *arrow.Schema) (*deadLetterStream, error) {
+	if tableName == "" {
+		tableName = "unknown"
+	}
+	path := filepath.Join(d.dir, fmt.Sprintf("%s-%d.arrows", tableName, time.Now().UnixNano()))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter file: %w", err)
+	}
+	return &deadLetterStream{
+		file:   f,
+		writer: ipc.NewWriter(f, ipc.WithSchema(sc), ipc.WithAllocator(memory.DefaultAllocator)),
+		schema: sc,
+	}, nil
+}
+
+func (s *deadLetterStream) close() error {
+	return errors.Join(s.writer.Close(), s.file.Close())
+}
+
+// withErrorColumn returns the record with an additional DeadLetterErrorColumn holding err for every row.
+func withErrorColumn(record arrow.RecordBatch, err error) arrow.RecordBatch {
+	sc := record.Schema()
+	md := sc.Metadata()
+	fields := append(sc.Fields(), arrow.Field{Name: DeadLetterErrorColumn, Type: arrow.BinaryTypes.String, Nullable: true})
+	bldr := array.NewStringBuilder(memory.DefaultAllocator)
+	defer bldr.Release()
+	for range record.NumRows() {
+		bldr.Append(err.Error())
+	}
+	errArr := bldr.NewArray()
+	defer errArr.Release()
+	cols := append(append(make([]arrow.Array, 0, len(fields)), record.Columns()...), errArr)
+	return array.NewRecordBatch(arrow.NewSchema(fields, &md), cols, record.NumRows())
+}
@@ -0,0 +1,149 @@
+package writers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+)
+
+// PrimaryKeyDeduplicator collapses the rows of insert batches having the same primary key in the same table, keeping
+// the last one, for destinations whose upserts fail when a batch affects the same row twice.
+// Rows of tables without primary keys are left untouched.
+// Only rows of the same batch are compared: a primary key written in two batches is written twice, and left to the
+// upsert of the destination.
+type PrimaryKeyDeduplicator struct {
+	Logger zerolog.Logger
+
+	dropped atomic.Int64
+}
+
+// DroppedRows returns the number of duplicate rows dropped so far.
+func (d *PrimaryKeyDeduplicator) DroppedRows() int64 {
+	return d.dropped.Load()
+}
+
+// Deduplicate returns the inserts without the rows whose primary key appears again later in the batch.
+// The order of the remaining rows is kept, and the records of the inserts without duplicates are returned as-is.
+func (d *PrimaryKeyDeduplicator) Deduplicate(messages message.WriteInserts) message.WriteInserts {
+	type rowRef struct {
+		msg int
+		row int64
+	}
+	pkIndexes := make(map[*arrow.Schema][]int)
+	keys := make([][]string, len(messages))
+	last := make(map[string]rowRef)
+	var keyed int64
+	for i, msg := range messages {
+		sc := msg.Record.Schema()
+		indexes, ok := pkIndexes[sc]
+		if !ok {
+			indexes = primaryKeysIndexes(sc)
+			pkIndexes[sc] = indexes
+		}
+		if len(indexes) == 0 {
+			continue
+		}
+		tableName, _ := sc.Metadata().GetValue(schema.MetadataTableName)
+		keys[i] = make([]string, msg.Record.NumRows())
+		for row := range msg.Record.NumRows() {
+			key := primaryKeyString(tableName, msg.Record, indexes, row)
+			keys[i][row] = key
+			last[key] = rowRef{msg: i, row: row}
+		}
+		keyed += msg.Record.NumRows()
+	}
+	if int64(len(last)) == keyed {
+		return messages
+	}
+
+	deduplicated := make(message.WriteInserts, 0, len(messages))
+	for i, msg := range messages {
+		if keys[i] == nil {
+			deduplicated = append(deduplicated, msg)
+			continue
+		}
+		rows := msg.Record.NumRows()
+		start := int64(0)
+		for row := range rows {
+			if last[keys[i][row]] == (rowRef{msg: i, row: row}) {
+				continue
+			}
+			if row > start {
+				deduplicated = append(deduplicated, &message.WriteInsert{Record: msg.Record.NewSlice(start, row)})
+			}
+			start = row + 1
+		}
+		switch {
+		case start == 0:
+			deduplicated = append(deduplicated, msg)
+		case start < rows:
+			deduplicated = append(deduplicated, &message.WriteInsert{Record: msg.Record.NewSlice(start, rows)})
+		}
+	}
+
+	dropped := keyed - int64(len(last))
+	d.dropped.Add(dropped)
+	d.Logger.Debug().Int64("dropped", dropped).Msg("dropped rows with duplicate primary keys from batch")
+	return deduplicated
+}
+
+// WrapBatch returns an InsertBatchFunc that deduplicates the batch before writing it with write.
+func (d *PrimaryKeyDeduplicator) WrapBatch(write InsertBatchFunc) InsertBatchFunc {
+	return func(ctx context.Context, messages message.WriteInserts) error {
+		return write(ctx, d.Deduplicate(messages))
+	}
+}
+
+// WrapStream returns an InsertStreamFunc that buffers the inserts sent on the channel, and deduplicates them before
+// sending them to write on a new channel.
+func (d *PrimaryKeyDeduplicator) WrapStream(write InsertStreamFunc) InsertStreamFunc {
+	return func(ctx context.Context, ch <-chan *message.WriteInsert) error {
+		var messages message.WriteInserts
+		for msg := range ch {
+			messages = append(messages, msg)
+		}
+		messages = d.Deduplicate(messages)
+		deduplicated := make(chan *message.WriteInsert, len(messages))
+		for _, msg := range messages {
+			deduplicated <- msg
+		}
+		close(deduplicated)
+		return write(ctx, deduplicated)
+	}
+}
+
+func primaryKeysIndexes(sc *arrow.Schema) []int {
+	table, err := schema.NewTableFromArrowSchema(sc)
+	if err != nil {
+		return nil
+	}
+	return table.PrimaryKeysIndexes()
+}
+
+// primaryKeyString returns a string identifying the primary key of the row in the table.
+// Every part is prefixed with its length, and nulls with a marker no length starts with, so that distinct keys can't
+// produce the same string whatever their values contain.
+func primaryKeyString(tableName string, record arrow.RecordBatch, indexes []int, row int64) string {
+	var sb strings.Builder
+	writePart := func(part string) {
+		sb.WriteString(strconv.Itoa(len(part)))
+		sb.WriteByte(':')
+		sb.WriteString(part)
+	}
+	writePart(tableName)
+	for _, i := range indexes {
+		col := record.Column(i)
+		if col.IsNull(int(row)) {
+			sb.WriteByte('-')
+			continue
+		}
+		writePart(col.ValueStr(int(row)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,131 @@
+package writers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/writers"
+	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/mixedbatchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/streamingbatchwriter"
+	"github.com/stretchr/testify/require"
+)
+
+func pkRecord(tableName string, primaryKey bool, ids []int64, values []string) arrow.RecordBatch {
+	table := &schema.Table{Name: tableName, Columns: schema.ColumnList{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: primaryKey},
+		{Name: "value", Type: arrow.BinaryTypes.String},
+	}}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues(ids, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues(values, nil)
+	return bldr.NewRecordBatch()
+}
+
+func insertValues(messages message.WriteInserts) []string {
+	var values []string
+	for _, msg := range messages {
+		for i := 0; i < int(msg.Record.NumRows()); i++ {
+			tableName, _ := msg.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+			values = append(values, tableName+":"+msg.Record.Column(1).(*array.String).Value(i))
+		}
+	}
+	return values
+}
+
+func TestPrimaryKeyDeduplicator(t *testing.T) {
+	d := &writers.PrimaryKeyDeduplicator{}
+	messages := message.WriteInserts{
+		{Record: pkRecord("table_a", true, []int64{1, 2, 1}, []string{"a", "b", "c"})},
+		{Record: pkRecord("table_b", false, []int64{1, 1}, []string{"d", "e"})},
+		{Record: pkRecord("table_c", true, []int64{1}, []string{"f"})},
+		{Record: pkRecord("table_a", true, []int64{3, 2, 4}, []string{"g", "h", "i"})},
+	}
+	deduplicated := d.Deduplicate(messages)
+	require.Equal(t, []string{"table_a:c", "table_b:d", "table_b:e", "table_c:f", "table_a:g", "table_a:h", "table_a:i"}, insertValues(deduplicated))
+	require.EqualValues(t, 2, d.DroppedRows())
+
+	// batches without duplicates are returned as-is
+	deduplicated = d.Deduplicate(deduplicated)
+	require.Len(t, deduplicated, 4)
+	require.EqualValues(t, 2, d.DroppedRows())
+}
+
+func TestPrimaryKeyDeduplicator_DistinctKeys(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{
+		{Name: "key1", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+		{Name: "key2", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+	}}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	key1, key2 := bldr.Field(0).(*array.StringBuilder), bldr.Field(1).(*array.StringBuilder)
+	// keys that would be the same if their parts were only separated
+	key1.AppendValues([]string{"a\x00", "a"}, nil)
+	key2.AppendValues([]string{"b", "\x00b"}, nil)
+	// a null against a value looking like a marker
+	key1.AppendValues([]string{"c", "c"}, nil)
+	key2.AppendNull()
+	key2.Append("-")
+
+	d := &writers.PrimaryKeyDeduplicator{}
+	messages := message.WriteInserts{{Record: bldr.NewRecordBatch()}}
+	deduplicated := d.Deduplicate(messages)
+	require.Equal(t, messages, deduplicated)
+	require.Zero(t, d.DroppedRows())
+}
+
+func TestPrimaryKeyDeduplicator_SchemaChange(t *testing.T) {
+	d := &writers.PrimaryKeyDeduplicator{}
+	// the primary key of the table was dropped during the batch
+	messages := message.WriteInserts{
+		{Record: pkRecord("test_table", true, []int64{1}, []string{"a"})},
+		{Record: pkRecord("test_table", false, []int64{1}, []string{"b"})},
+	}
+	deduplicated := d.Deduplicate(messages)
+	require.Equal(t, []string{"test_table:a", "test_table:b"}, insertValues(deduplicated))
+	require.Zero(t, d.DroppedRows())
+}
+
+func TestWithPrimaryKeyDeduplicator(t *testing.T) {
+	type closer interface {
+		Close(context.Context) error
+	}
+	cases := map[string]func(write writers.InsertBatchFunc, d *writers.PrimaryKeyDeduplicator) (writers.Writer, error){
+		"BatchWriter": func(write writers.InsertBatchFunc, d *writers.PrimaryKeyDeduplicator) (writers.Writer, error) {
+			return batchwriter.New(insertFuncBatchClient{write: write}, batchwriter.WithPrimaryKeyDeduplicator(d))
+		},
+		"MixedBatchWriter": func(write writers.InsertBatchFunc, d *writers.PrimaryKeyDeduplicator) (writers.Writer, error) {
+			return mixedbatchwriter.New(insertFuncMixedClient{write: write}, mixedbatchwriter.WithPrimaryKeyDeduplicator(d))
+		},
+		"StreamingBatchWriter": func(write writers.InsertBatchFunc, d *writers.PrimaryKeyDeduplicator) (writers.Writer, error) {
+			return streamingbatchwriter.New(insertFuncStreamingClient{write: write}, streamingbatchwriter.WithPrimaryKeyDeduplicator(d))
+		},
+	}
+	for name, newWriter := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			w := &poisonWriter{}
+			d := &writers.PrimaryKeyDeduplicator{}
+			wr, err := newWriter(w.write, d)
+			require.NoError(t, err)
+
+			ch := make(chan message.WriteMessage, 2)
+			ch <- &message.WriteInsert{Record: pkRecord("test_table", true, []int64{1, 2, 3}, []string{"a", "b", "c"})}
+			ch <- &message.WriteInsert{Record: pkRecord("test_table", true, []int64{2, 4}, []string{"d", "e"})}
+			close(ch)
+			require.NoError(t, wr.Write(ctx, ch))
+			if c, ok := wr.(closer); ok {
+				require.NoError(t, c.Close(ctx))
+			}
+
+			require.Equal(t, []int64{1, 2, 3, 4}, w.values())
+			require.EqualValues(t, 1, d.DroppedRows())
+		})
+	}
+}
@@ -25,6 +25,10 @@ type MixedBatchWriter struct {
 	batchSizeBytes int64
 	batchTimeout   time.Duration
 	tickerFn       writers.TickerFunc
+	insertRetrier  *writers.InsertRetrier
+	limiter        *writers.ConcurrencyLimiter
+	deduplicator   *writers.PrimaryKeyDeduplicator
+	compactor      *writers.RecordCompactor
 }
 
 // Assert at compile-time that MixedBatchWriter implements the Writer interface
@@ -56,6 +60,37 @@ func WithBatchTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithInsertRetrier retries, bisects and dead-letters the batches failing in InsertBatch.
+func WithInsertRetrier(retrier *writers.InsertRetrier) Option {
+	return func(p *MixedBatchWriter) {
+		p.insertRetrier = retrier
+	}
+}
+
+// WithConcurrencyLimiter limits the concurrent InsertBatch calls, which is useful when the limiter is shared with other
+// writers, as MixedBatchWriter writes its batches serially. The limits of every table in a batch apply to it.
+func WithConcurrencyLimiter(limiter *writers.ConcurrencyLimiter) Option {
+	return func(p *MixedBatchWriter) {
+		p.limiter = limiter
+	}
+}
+
+// WithPrimaryKeyDeduplicator drops the rows having the same primary key within a batch before InsertBatch, keeping the
+// last one.
+func WithPrimaryKeyDeduplicator(deduplicator *writers.PrimaryKeyDeduplicator) Option {
+	return func(p *MixedBatchWriter) {
+		p.deduplicator = deduplicator
+	}
+}
+
+// WithRecordCompactor merges the records of a batch into fewer, larger records before InsertBatch. Records of different
+// tables are never merged, but the records of a batch are grouped by table.
+func WithRecordCompactor(compactor *writers.RecordCompactor) Option {
+	return func(p *MixedBatchWriter) {
+		p.compactor = compactor
+	}
+}
+
 func withTickerFn(tickerFn writers.TickerFunc) Option {
 	return func(p *MixedBatchWriter) {
 		p.tickerFn = tickerFn
@@ -89,9 +124,25 @@ func (w *MixedBatchWriter) Write(ctx context.Context, msgChan <-chan message.Wri
 		batch:     make([]*message.WriteMigrateTable, 0, w.batchSize),
 		writeFunc: w.client.MigrateTableBatch,
 	}
+	var insertFunc writers.InsertBatchFunc = w.client.InsertBatch
+	if w.limiter != nil {
+		insertFunc = w.limiter.LimitBatch(insertFunc)
+	}
+	if w.insertRetrier != nil {
+		write := insertFunc
+		insertFunc = func(ctx context.Context, messages message.WriteInserts) error {
+			return w.insertRetrier.Write(ctx, messages, write)
+		}
+	}
+	if w.compactor != nil {
+		insertFunc = w.compactor.WrapBatch(insertFunc)
+	}
+	if w.deduplicator != nil {
+		insertFunc = w.deduplicator.WrapBatch(insertFunc)
+	}
 	insert := &insertBatchManager{
 		batch:     make([]*message.WriteInsert, 0, w.batchSize),
-		writeFunc: w.client.InsertBatch,
+		writeFunc: insertFunc,
 		limit:     batch.CappedAt(w.batchSizeBytes, w.batchSize),
 		logger:    w.logger,
 	}
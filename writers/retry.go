@@ -0,0 +1,298 @@
+package writers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/util"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+)
+
+// InsertRetrier retries insert batches that failed to be written. Once the retries of a batch are exhausted, the batch
+// is bisected to isolate the rows that can't be written: the halves that succeed are written, and every row that still
+// fails on its own is sent to the DeadLetter, so that the healthy rows keep flowing.
+//
+// RetryWriter uses it to retry the inserts of any Writer. It can also be set with the WithInsertRetrier option of
+// batchwriter, mixedbatchwriter and streamingbatchwriter, which retries the batches sent to their client instead.
+type InsertRetrier struct {
+	// Policy controls the retries of failed batches. The halves of a bisected batch are written once.
+	// A batch isn't bisected if its attempts are exhausted and Policy.Retryable reports the last error as retryable:
+	// such an error affects the whole batch, such as an outage of the destination, rather than some of its rows.
+	Policy schema.RetryPolicy
+	// DeadLetter receives the rows that can't be written along with their error.
+	// If it's nil, the errors of these rows are returned once the rest of the batch was written.
+	DeadLetter DeadLetter
+	Logger     zerolog.Logger
+}
+
+type (
+	// InsertBatchFunc writes a batch of inserts.
+	InsertBatchFunc func(ctx context.Context, messages message.WriteInserts) error
+	// InsertStreamFunc writes the inserts sent on a channel until it's closed.
+	InsertStreamFunc func(ctx context.Context, messages <-chan *message.WriteInsert) error
+)
+
+// Write writes messages with write, retrying and bisecting the batch if it fails.
+// It returns an error if the context is done, if the error is retryable, or if rows can't be written and there's no
+// DeadLetter.
+func (r *InsertRetrier) Write(ctx context.Context, messages message.WriteInserts, write InsertBatchFunc) error {
+	retries, err := r.Policy.Do(ctx, func() error { return write(ctx, messages) }, func(retry int, err error) {
+		r.Logger.Warn().Err(err).Int("retry", retry).Int64("len", countRows(messages)).Msg("retrying failed batch")
+	})
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if r.Policy.Retryable != nil && r.Policy.Retryable(err) {
+		// the error isn't caused by the rows, such as an outage of the destination
+		return err
+	}
+	r.Logger.Warn().Err(err).Int("retries", retries).Int64("len", countRows(messages)).Msg("bisecting failed batch")
+	return r.bisect(ctx, messages, write, err)
+}
+
+// WrapStream returns an InsertStreamFunc that buffers the inserts sent on the channel, then writes them with write as a
+// single batch using Write. The batch is sent again on a new channel for every attempt.
+func (r *InsertRetrier) WrapStream(write InsertStreamFunc) InsertStreamFunc {
+	writeBatch := func(ctx context.Context, messages message.WriteInserts) error {
+		ch := make(chan *message.WriteInsert, len(messages))
+		for _, msg := range messages {
+			ch <- msg
+		}
+		close(ch)
+		return write(ctx, ch)
+	}
+	return func(ctx context.Context, ch <-chan *message.WriteInsert) error {
+		var messages message.WriteInserts
+		for msg := range ch {
+			messages = append(messages, msg)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		return r.Write(ctx, messages, writeBatch)
+	}
+}
+
+// bisect writes both halves of the batch that failed with err, bisecting them again if they fail.
+func (r *InsertRetrier) bisect(ctx context.Context, messages message.WriteInserts, write InsertBatchFunc, err error) error {
+	rows := countRows(messages)
+	if rows <= 1 {
+		return r.deadLetter(messages, err)
+	}
+	left, right, slices := splitInserts(messages, rows/2)
+	defer func() {
+		for _, slice := range slices {
+			slice.Release()
+		}
+	}()
+
+	halves := []message.WriteInserts{left, right}
+	halfErrs := make([]error, len(halves))
+	for i, half := range halves {
+		halfErrs[i] = write(ctx, half)
+		if halfErrs[i] != nil && ctx.Err() != nil {
+			return halfErrs[i]
+		}
+	}
+	var errs []error
+	for i, half := range halves {
+		if halfErrs[i] == nil {
+			continue
+		}
+		if err := r.bisect(ctx, half, write, halfErrs[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *InsertRetrier) deadLetter(messages message.WriteInserts, err error) error {
+	var errs []error
+	for _, msg := range messages {
+		if msg.Record.NumRows() == 0 {
+			continue
+		}
+		tableName, _ := msg.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+		if r.DeadLetter == nil {
+			errs = append(errs, fmt.Errorf("failed to write row of table %s: %w", tableName, err))
+			continue
+		}
+		r.Logger.Error().Err(err).Str("table", tableName).Msg("failed to write row, sending it to the dead letter")
+		if dlErr := r.DeadLetter.WriteDeadLetter(msg.Record, err); dlErr != nil {
+			errs = append(errs, fmt.Errorf("failed to write row of table %s to the dead letter: %w (write error: %w)", tableName, dlErr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func countRows(messages message.WriteInserts) int64 {
+	var rows int64
+	for _, msg := range messages {
+		rows += msg.Record.NumRows()
+	}
+	return rows
+}
+
+// splitInserts splits the inserts in two: the first n rows and the rest, slicing the record that spans both.
+// The slices are returned as well, to be released once the halves aren't used anymore.
+func splitInserts(messages message.WriteInserts, n int64) (left, right message.WriteInserts, slices []arrow.RecordBatch) {
+	for _, msg := range messages {
+		rows := msg.Record.NumRows()
+		switch {
+		case n <= 0:
+			right = append(right, msg)
+		case rows <= n:
+			left = append(left, msg)
+		default:
+			leftSlice, rightSlice := msg.Record.NewSlice(0, n), msg.Record.NewSlice(n, rows)
+			left = append(left, &message.WriteInsert{Record: leftSlice})
+			right = append(right, &message.WriteInsert{Record: rightSlice})
+			slices = append(slices, leftSlice, rightSlice)
+		}
+		n -= rows
+	}
+	return left, right, slices
+}
+
+const (
+	defaultRetryBatchSize      = 10000
+	defaultRetryBatchSizeBytes = 5 * 1024 * 1024 // 5 MiB
+	defaultRetryBatchTimeout   = 20 * time.Second
+)
+
+// Flusher is implemented by the writers that buffer messages across calls to Write, such as batchwriter.BatchWriter.
+// Flush writes the buffered messages, and returns the errors of the messages that failed to be written.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// RetryWriter is a Writer decorator retrying the inserts that Writer fails to write with Retrier.
+//
+// The inserts of every table are buffered in batches, each written with a separate call to Writer.Write (followed by
+// Flush if Writer is a Flusher), so that a failing batch can be retried, bisected and dead-lettered without failing the
+// others. A batch is written once it reaches BatchSize rows or BatchSizeBytes bytes, BatchTimeout after the last
+// write, or before any other message is written. The other messages are written as they come, and their errors are
+// returned.
+//
+// Writer must report the errors of the inserts it fails to write by the time Write (or Flush) returns. The rows of a
+// retried batch that were already written are written again, so Writer should upsert them or write a batch at once.
+type RetryWriter struct {
+	Writer  Writer
+	Retrier *InsertRetrier
+	// BatchSize is the maximum number of rows in a batch. 0 means 10000 rows.
+	BatchSize int64
+	// BatchSizeBytes is the maximum size of a batch in bytes. 0 means 5 MiB.
+	BatchSizeBytes int64
+	// BatchTimeout is the time after which the buffered inserts are written. 0 means 20 seconds.
+	BatchTimeout time.Duration
+}
+
+// Assert at compile-time that RetryWriter implements the Writer interface
+var _ Writer = (*RetryWriter)(nil)
+
+type retryBatch struct {
+	messages message.WriteInserts
+	rows     int64
+	bytes    int64
+}
+
+func (w *RetryWriter) Write(ctx context.Context, msgs <-chan message.WriteMessage) error {
+	batchSize, batchSizeBytes, batchTimeout := w.BatchSize, w.BatchSizeBytes, w.BatchTimeout
+	if batchSize <= 0 {
+		batchSize = defaultRetryBatchSize
+	}
+	if batchSizeBytes <= 0 {
+		batchSizeBytes = defaultRetryBatchSizeBytes
+	}
+	if batchTimeout <= 0 {
+		batchTimeout = defaultRetryBatchTimeout
+	}
+
+	batches := make(map[string]*retryBatch)
+	flushTable := func(tableName string) error {
+		b := batches[tableName]
+		delete(batches, tableName)
+		if b == nil {
+			return nil
+		}
+		return w.Retrier.Write(ctx, b.messages, w.writeInserts)
+	}
+	flushAll := func() error {
+		var errs []error
+		for tableName := range batches {
+			errs = append(errs, flushTable(tableName))
+		}
+		return errors.Join(errs...)
+	}
+
+	ticker := NewTicker(batchTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return flushAll()
+			}
+			insert, ok := msg.(*message.WriteInsert)
+			if !ok {
+				if err := flushAll(); err != nil {
+					return err
+				}
+				if err := w.write(ctx, msg); err != nil {
+					return err
+				}
+				continue
+			}
+			if insert.Record.NumRows() == 0 {
+				continue
+			}
+			tableName, _ := insert.Record.Schema().Metadata().GetValue(schema.MetadataTableName)
+			b := batches[tableName]
+			if b == nil {
+				b = &retryBatch{}
+				batches[tableName] = b
+			}
+			b.messages = append(b.messages, insert)
+			b.rows += insert.Record.NumRows()
+			b.bytes += util.TotalRecordSize(insert.Record)
+			if b.rows >= batchSize || b.bytes >= batchSizeBytes {
+				if err := flushTable(tableName); err != nil {
+					return err
+				}
+			}
+		case <-ticker.Chan():
+			if err := flushAll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *RetryWriter) writeInserts(ctx context.Context, inserts message.WriteInserts) error {
+	msgs := make([]message.WriteMessage, len(inserts))
+	for i, msg := range inserts {
+		msgs[i] = msg
+	}
+	return w.write(ctx, msgs...)
+}
+
+// write writes the messages with a single call to Writer.Write, flushing Writer if it's a Flusher.
+func (w *RetryWriter) write(ctx context.Context, msgs ...message.WriteMessage) error {
+	ch := make(chan message.WriteMessage, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	if err := w.Writer.Write(ctx, ch); err != nil {
+		return err
+	}
+	if f, ok := w.Writer.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
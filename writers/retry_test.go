@@ -0,0 +1,285 @@
+package writers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/writers"
+	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/mixedbatchwriter"
+	"github.com/cloudquery/plugin-sdk/v4/writers/streamingbatchwriter"
+	"github.com/stretchr/testify/require"
+)
+
+var errPoisonRow = errors.New("poison row")
+
+// poisonWriter rejects batches containing a negative value, and records the values of the batches it accepts.
+type poisonWriter struct {
+	mu      sync.Mutex
+	calls   int
+	written []int64
+}
+
+func (w *poisonWriter) write(_ context.Context, messages message.WriteInserts) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls++
+	var values []int64
+	for _, msg := range messages {
+		values = append(values, msg.Record.Column(0).(*array.Int64).Int64Values()...)
+	}
+	if i := slices.IndexFunc(values, func(v int64) bool { return v < 0 }); i >= 0 {
+		return fmt.Errorf("%w: %d", errPoisonRow, values[i])
+	}
+	w.written = append(w.written, values...)
+	return nil
+}
+
+func (w *poisonWriter) values() []int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	values := slices.Clone(w.written)
+	slices.Sort(values)
+	return values
+}
+
+func poisonRecord(values ...int64) arrow.RecordBatch {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "value", Type: arrow.PrimitiveTypes.Int64}}}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues(values, nil)
+	return bldr.NewRecordBatch()
+}
+
+func readDeadLetters(t *testing.T, dir string) ([]int64, []string) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "test_table-*.arrows"))
+	require.NoError(t, err)
+	var values []int64
+	var errs []string
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		rdr, err := ipc.NewReader(f)
+		require.NoError(t, err)
+		for rdr.Next() {
+			rec := rdr.RecordBatch()
+			values = append(values, rec.Column(0).(*array.Int64).Int64Values()...)
+			require.Equal(t, writers.DeadLetterErrorColumn, rec.Schema().Field(1).Name)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				errs = append(errs, rec.Column(1).(*array.String).Value(i))
+			}
+		}
+		require.NoError(t, rdr.Err())
+		rdr.Release()
+		require.NoError(t, f.Close())
+	}
+	slices.Sort(values)
+	return values, errs
+}
+
+func TestInsertRetrier_DeadLetter(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	deadLetter, err := writers.NewDeadLetterFile(dir)
+	require.NoError(t, err)
+
+	w := &poisonWriter{}
+	retrier := &writers.InsertRetrier{DeadLetter: deadLetter}
+	messages := message.WriteInserts{
+		{Record: poisonRecord(1, 2, -3, 4, 5)},
+		{Record: poisonRecord(6, 7, 8, -9)},
+	}
+	require.NoError(t, retrier.Write(ctx, messages, w.write))
+	require.NoError(t, deadLetter.Close())
+
+	require.Equal(t, []int64{1, 2, 4, 5, 6, 7, 8}, w.values())
+	values, errs := readDeadLetters(t, dir)
+	require.Equal(t, []int64{-9, -3}, values)
+	require.Equal(t, []string{"poison row: -3", "poison row: -9"}, errs)
+}
+
+func TestInsertRetrier_NoDeadLetter(t *testing.T) {
+	w := &poisonWriter{}
+	retrier := &writers.InsertRetrier{}
+	err := retrier.Write(context.Background(), message.WriteInserts{{Record: poisonRecord(1, -2, 3)}}, w.write)
+	require.ErrorIs(t, err, errPoisonRow)
+	require.ErrorContains(t, err, "failed to write row of table test_table")
+	require.Equal(t, []int64{1, 3}, w.values())
+}
+
+func TestInsertRetrier_Retryable(t *testing.T) {
+	errOutage := errors.New("destination unavailable")
+	calls := 0
+	write := func(context.Context, message.WriteInserts) error {
+		calls++
+		return errOutage
+	}
+	dir := t.TempDir()
+	deadLetter, err := writers.NewDeadLetterFile(dir)
+	require.NoError(t, err)
+	retrier := &writers.InsertRetrier{
+		Policy:     schema.RetryPolicy{Retryable: func(err error) bool { return errors.Is(err, errOutage) }},
+		DeadLetter: deadLetter,
+	}
+	err = retrier.Write(context.Background(), message.WriteInserts{{Record: poisonRecord(1, 2, 3)}}, write)
+	require.ErrorIs(t, err, errOutage)
+	require.NoError(t, deadLetter.Close())
+	// retryable errors aren't bisected
+	require.Equal(t, 1, calls)
+	values, _ := readDeadLetters(t, dir)
+	require.Empty(t, values)
+}
+
+func TestInsertRetrier_SameErrorHalves(t *testing.T) {
+	calls := 0
+	write := func(context.Context, message.WriteInserts) error {
+		calls++
+		return errors.New("connection refused")
+	}
+	dir := t.TempDir()
+	deadLetter, err := writers.NewDeadLetterFile(dir)
+	require.NoError(t, err)
+	retrier := &writers.InsertRetrier{DeadLetter: deadLetter}
+	require.NoError(t, retrier.Write(context.Background(), message.WriteInserts{{Record: poisonRecord(1, 2, 3, 4)}}, write))
+	require.NoError(t, deadLetter.Close())
+	// errors that aren't retryable are blamed on the rows, even if both halves fail the same way
+	require.Equal(t, 7, calls)
+	values, errs := readDeadLetters(t, dir)
+	require.Equal(t, []int64{1, 2, 3, 4}, values)
+	require.Equal(t, []string{"connection refused", "connection refused", "connection refused", "connection refused"}, errs)
+}
+
+func TestInsertRetrier_Retry(t *testing.T) {
+	w := &poisonWriter{}
+	failures := 1
+	write := func(ctx context.Context, messages message.WriteInserts) error {
+		if failures > 0 {
+			failures--
+			return errors.New("transient")
+		}
+		return w.write(ctx, messages)
+	}
+	retrier := &writers.InsertRetrier{Policy: schema.RetryPolicy{MaxAttempts: 2}}
+	require.NoError(t, retrier.Write(context.Background(), message.WriteInserts{{Record: poisonRecord(1, 2, 3)}}, write))
+	require.Equal(t, []int64{1, 2, 3}, w.values())
+	// the batch was written at once, without bisecting
+	require.Equal(t, 1, w.calls)
+}
+
+func TestWithInsertRetrier(t *testing.T) {
+	type closer interface {
+		Close(context.Context) error
+	}
+	cases := map[string]func(write writers.InsertBatchFunc, retrier *writers.InsertRetrier) (writers.Writer, error){
+		"BatchWriter": func(write writers.InsertBatchFunc, retrier *writers.InsertRetrier) (writers.Writer, error) {
+			return batchwriter.New(insertFuncBatchClient{write: write}, batchwriter.WithInsertRetrier(retrier))
+		},
+		"MixedBatchWriter": func(write writers.InsertBatchFunc, retrier *writers.InsertRetrier) (writers.Writer, error) {
+			return mixedbatchwriter.New(insertFuncMixedClient{write: write}, mixedbatchwriter.WithInsertRetrier(retrier))
+		},
+		"StreamingBatchWriter": func(write writers.InsertBatchFunc, retrier *writers.InsertRetrier) (writers.Writer, error) {
+			return streamingbatchwriter.New(insertFuncStreamingClient{write: write}, streamingbatchwriter.WithInsertRetrier(retrier))
+		},
+	}
+	for name, newWriter := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			deadLetter, err := writers.NewDeadLetterFile(dir)
+			require.NoError(t, err)
+
+			w := &poisonWriter{}
+			wr, err := newWriter(w.write, &writers.InsertRetrier{DeadLetter: deadLetter})
+			require.NoError(t, err)
+
+			ch := make(chan message.WriteMessage, 2)
+			ch <- &message.WriteInsert{Record: poisonRecord(1, -2, 3)}
+			ch <- &message.WriteInsert{Record: poisonRecord(4, 5)}
+			close(ch)
+			require.NoError(t, wr.Write(ctx, ch))
+			if c, ok := wr.(closer); ok {
+				require.NoError(t, c.Close(ctx))
+			}
+			require.NoError(t, deadLetter.Close())
+
+			require.Equal(t, []int64{1, 3, 4, 5}, w.values())
+			values, _ := readDeadLetters(t, dir)
+			require.Equal(t, []int64{-2}, values)
+		})
+	}
+}
+
+func TestRetryWriter(t *testing.T) {
+	type closer interface {
+		Close(context.Context) error
+	}
+	cases := map[string]func(write writers.InsertBatchFunc) (writers.Writer, error){
+		"BatchWriter": func(write writers.InsertBatchFunc) (writers.Writer, error) {
+			return batchwriter.New(insertFuncBatchClient{write: write})
+		},
+		"MixedBatchWriter": func(write writers.InsertBatchFunc) (writers.Writer, error) {
+			return mixedbatchwriter.New(insertFuncMixedClient{write: write})
+		},
+		"StreamingBatchWriter": func(write writers.InsertBatchFunc) (writers.Writer, error) {
+			return streamingbatchwriter.New(insertFuncStreamingClient{write: write})
+		},
+	}
+	for name, newWriter := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			deadLetter, err := writers.NewDeadLetterFile(dir)
+			require.NoError(t, err)
+
+			w := &poisonWriter{}
+			inner, err := newWriter(w.write)
+			require.NoError(t, err)
+			wr := &writers.RetryWriter{Writer: inner, Retrier: &writers.InsertRetrier{DeadLetter: deadLetter}, BatchSize: 4}
+
+			ch := make(chan message.WriteMessage, 3)
+			ch <- &message.WriteInsert{Record: poisonRecord(1, -2, 3)}
+			ch <- &message.WriteInsert{Record: poisonRecord(4, 5)}
+			ch <- &message.WriteInsert{Record: poisonRecord(6, -7)}
+			close(ch)
+			require.NoError(t, wr.Write(ctx, ch))
+			if c, ok := inner.(closer); ok {
+				require.NoError(t, c.Close(ctx))
+			}
+			require.NoError(t, deadLetter.Close())
+
+			require.Equal(t, []int64{1, 3, 4, 5, 6}, w.values())
+			values, _ := readDeadLetters(t, dir)
+			require.Equal(t, []int64{-7, -2}, values)
+		})
+	}
+}
+
+func TestRetryWriter_Error(t *testing.T) {
+	errOutage := errors.New("destination unavailable")
+	inner, err := mixedbatchwriter.New(insertFuncMixedClient{write: func(context.Context, message.WriteInserts) error {
+		return errOutage
+	}})
+	require.NoError(t, err)
+	wr := &writers.RetryWriter{
+		Writer:  inner,
+		Retrier: &writers.InsertRetrier{Policy: schema.RetryPolicy{Retryable: func(err error) bool { return errors.Is(err, errOutage) }}},
+	}
+
+	ch := make(chan message.WriteMessage, 1)
+	ch <- &message.WriteInsert{Record: poisonRecord(1, 2)}
+	close(ch)
+	require.ErrorIs(t, wr.Write(context.Background(), ch), errOutage)
+}
@@ -17,6 +17,10 @@
 //
 // Each handler can get invoked multiple times as new batches are processed.
 // Handlers get invoked only if there's a message of that type at hand: First message of the batch is immediately available in the channel.
+//
+// Some insert options trade the streaming of inserts for their feature, as WriteTable gets the inserts of a batch only once it's complete:
+//   - WithInsertRetrier buffers every batch in memory so that it can be sent again when retried, holding up to the batch size in bytes per table.
+//   - WithPrimaryKeyDeduplicator buffers every batch in memory as well, as the last row of a primary key is only known once the batch is complete.
 package streamingbatchwriter
 
 import (
@@ -67,6 +71,10 @@ type StreamingBatchWriter struct {
 	batchTimeout   time.Duration
 	batchSizeRows  int64
 	batchSizeBytes int64
+	insertRetrier  *writers.InsertRetrier
+	limiter        *writers.ConcurrencyLimiter
+	deduplicator   *writers.PrimaryKeyDeduplicator
+	compactor      *writers.RecordCompactor
 
 	tickerFn writers.TickerFunc
 }
@@ -100,6 +108,39 @@ func WithBatchSizeBytes(size int64) Option {
 	}
 }
 
+// WithInsertRetrier retries, bisects and dead-letters the batches failing in WriteTable.
+// The inserts of every batch are buffered until the batch is complete, and sent again for every attempt.
+func WithInsertRetrier(retrier *writers.InsertRetrier) Option {
+	return func(p *StreamingBatchWriter) {
+		p.insertRetrier = retrier
+	}
+}
+
+// WithConcurrencyLimiter limits the concurrent WriteTable calls of the table workers.
+// A call holds its slot until its batch is complete, so it's best used with a batch timeout.
+func WithConcurrencyLimiter(limiter *writers.ConcurrencyLimiter) Option {
+	return func(p *StreamingBatchWriter) {
+		p.limiter = limiter
+	}
+}
+
+// WithPrimaryKeyDeduplicator drops the rows having the same primary key within a batch before WriteTable, keeping the
+// last one. The inserts of every batch are buffered until the batch is complete.
+func WithPrimaryKeyDeduplicator(deduplicator *writers.PrimaryKeyDeduplicator) Option {
+	return func(p *StreamingBatchWriter) {
+		p.deduplicator = deduplicator
+	}
+}
+
+// WithRecordCompactor merges the records of a batch into fewer, larger records before WriteTable.
+// The records are merged as they come, so only the record being merged is buffered, up to the MaxRows and MaxBytes of
+// the compactor.
+func WithRecordCompactor(compactor *writers.RecordCompactor) Option {
+	return func(p *StreamingBatchWriter) {
+		p.compactor = compactor
+	}
+}
+
 func withTickerFn(tickerFn writers.TickerFunc) Option {
 	return func(p *StreamingBatchWriter) {
 		p.tickerFn = tickerFn
@@ -300,9 +341,22 @@ func (w *StreamingBatchWriter) startWorker(ctx context.Context, errCh chan<- err
 			return nil
 		}
 
+		var writeFunc writers.InsertStreamFunc = w.client.WriteTable
+		if w.limiter != nil {
+			writeFunc = w.limiter.LimitStream(tableName, writeFunc)
+		}
+		if w.insertRetrier != nil {
+			writeFunc = w.insertRetrier.WrapStream(writeFunc)
+		}
+		if w.compactor != nil {
+			writeFunc = w.compactor.WrapStream(writeFunc)
+		}
+		if w.deduplicator != nil {
+			writeFunc = w.deduplicator.WrapStream(writeFunc)
+		}
 		worker = &streamingWorkerManager[*message.WriteInsert]{
 			ch:        make(chan *message.WriteInsert),
-			writeFunc: w.client.WriteTable,
+			writeFunc: writeFunc,
 			tableName: tableName,
 
 			flush: make(chan chan bool),
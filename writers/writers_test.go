@@ -217,3 +217,41 @@ func (streamingbatchwriterClient) WriteTable(_ context.Context, ch <-chan *messa
 }
 
 var _ streamingbatchwriter.Client = (*streamingbatchwriterClient)(nil)
+
+// insertFuncBatchClient, insertFuncMixedClient and insertFuncStreamingClient pass the inserts of their writer to write.
+type insertFuncBatchClient struct {
+	batchwriter.IgnoreMigrateTables
+	batchwriter.UnimplementedDeleteStale
+	batchwriter.UnimplementedDeleteRecord
+	write writers.InsertBatchFunc
+}
+
+func (c insertFuncBatchClient) WriteTableBatch(ctx context.Context, _ string, msgs message.WriteInserts) error {
+	return c.write(ctx, msgs)
+}
+
+type insertFuncMixedClient struct {
+	mixedbatchwriter.IgnoreMigrateTableBatch
+	mixedbatchwriter.UnimplementedDeleteStaleBatch
+	mixedbatchwriter.UnimplementedDeleteRecordsBatch
+	write writers.InsertBatchFunc
+}
+
+func (c insertFuncMixedClient) InsertBatch(ctx context.Context, msgs message.WriteInserts) error {
+	return c.write(ctx, msgs)
+}
+
+type insertFuncStreamingClient struct {
+	streamingbatchwriter.IgnoreMigrateTable
+	streamingbatchwriter.UnimplementedDeleteStale
+	streamingbatchwriter.UnimplementedDeleteRecords
+	write writers.InsertBatchFunc
+}
+
+func (c insertFuncStreamingClient) WriteTable(ctx context.Context, ch <-chan *message.WriteInsert) error {
+	var msgs message.WriteInserts
+	for m := range ch {
+		msgs = append(msgs, m)
+	}
+	return c.write(ctx, msgs)
+}